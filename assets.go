@@ -0,0 +1,92 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"go-crud-generator/config"
+)
+
+// Templates e arquivos estáticos embutidos no binário, que assim roda de qualquer diretório
+//
+//go:embed views/templates static
+var embeddedAssets embed.FS
+
+// overlayFS busca cada arquivo primeiro no diretório do usuário e, se ele não existir
+// lá, nos arquivos embutidos. Assim basta copiar e alterar só os arquivos que mudam.
+type overlayFS struct {
+	override fs.FS
+	base     fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	file, err := o.override.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.base.Open(name)
+	}
+	return file, err
+}
+
+// ReadDir lista os arquivos dos dois lados (usado por fs.Glob ao carregar os templates)
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(o.base, name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	overrides, overrideErr := fs.ReadDir(o.override, name)
+	if overrideErr != nil && !errors.Is(overrideErr, fs.ErrNotExist) {
+		return nil, overrideErr
+	}
+	if err != nil && overrideErr != nil {
+		return nil, err
+	}
+
+	byName := map[string]fs.DirEntry{}
+	for _, entry := range append(entries, overrides...) {
+		byName[entry.Name()] = entry
+	}
+	merged := make([]fs.DirEntry, 0, len(byName))
+	for _, entry := range byName {
+		merged = append(merged, entry)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name() < merged[j].Name() })
+	return merged, nil
+}
+
+// assetsFS retorna o diretório embutido sub, com os arquivos de dir por cima quando informado
+func assetsFS(sub, dir string) fs.FS {
+	base, err := fs.Sub(embeddedAssets, sub)
+	if err != nil {
+		panic(err) // sub é um dos diretórios do go:embed
+	}
+	if dir == "" {
+		return base
+	}
+	return overlayFS{override: os.DirFS(dir), base: base}
+}
+
+// templatesFS são os templates: os embutidos ou os de --templates-dir
+func templatesFS(cfg *config.Config) fs.FS {
+	return assetsFS("views/templates", cfg.TemplatesDir)
+}
+
+// staticFS são os arquivos servidos em /static/: os embutidos ou os de --static-dir
+func staticFS(cfg *config.Config) fs.FS {
+	return assetsFS("static", cfg.StaticDir)
+}
+
+// watchedFiles são os arquivos observados com --watch: o schema e, com --templates-dir,
+// os templates do usuário, listados de novo a cada verificação para incluir os criados
+// depois (os embutidos só mudam com um novo build)
+func watchedFiles(cfg *config.Config) []string {
+	files := []string{cfg.JSONSchemaPath}
+	if cfg.TemplatesDir != "" {
+		templates, _ := filepath.Glob(filepath.Join(cfg.TemplatesDir, "*.html"))
+		files = append(files, templates...)
+	}
+	return files
+}
@@ -1,9 +1,14 @@
 package config
 
 import (
-	"errors"
 	"flag"
+	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-crud-generator/models"
 )
 
 // Config armazena todas as configurações da aplicação
@@ -15,60 +20,312 @@ type Config struct {
 	DBPassword     string
 	JSONSchemaPath string
 	Port           string
+	Watch          bool   // Recarrega schema e templates quando os arquivos mudam
+	AutoMigrate    bool   // serve aplica a migração ao iniciar (senão exige o banco já migrado)
+	TemplatesDir   string // Templates que substituem os embutidos no binário ("" usa só os embutidos)
+	StaticDir      string // Arquivos estáticos que substituem os embutidos ("" usa só os embutidos)
+	Pagination     string // Navegação da lista: offset (páginas numeradas) ou keyset (cursor)
+	ListCount      string // Contagem de registros no modo keyset e em /api/records: exact, estimate ou none
+
+	// Tema padrão da página (o "theme" do schema tem precedência)
+	ThemeTitle           string
+	ThemeLogo            string
+	ThemePrimaryColor    string
+	ThemeBackgroundColor string
+
+	// Armazenamento de uploads (campos file/image)
+	Storage     string // local ou s3
+	UploadDir   string
+	S3Endpoint  string
+	S3Bucket    string
+	S3Region    string
+	S3AccessKey string
+	S3SecretKey string
+
+	// Origem da configuração
+	ConfigFile string // Arquivo de configuração usado ("" se nenhum)
+	Profile    string // Perfil do arquivo ("" usa só os valores comuns)
+
+	sources map[string]string // Origem de cada valor, pela chave (ver Entries)
+}
+
+// ValidationError indica configuração obrigatória ausente ou com valor inválido
+// (os erros de parse dos flags já são exibidos pelo próprio FlagSet)
+type ValidationError struct {
+	Message string
 }
 
-// LoadConfig carrega a configuração de CLI args ou variáveis de ambiente
-func LoadConfig() (*Config, error) {
-	cfg := &Config{}
+func (e *ValidationError) Error() string {
+	return e.Message
+}
 
-	// Define os flags da CLI
-	flag.StringVar(&cfg.DBHost, "db-host", "", "Database host")
-	flag.StringVar(&cfg.DBPort, "db-port", "", "Database port")
-	flag.StringVar(&cfg.DBUser, "db-user", "", "Database user")
-	flag.StringVar(&cfg.DBPassword, "db-psw", "", "Database password")
-	flag.StringVar(&cfg.DBName, "db-name", "", "Database name")
-	flag.StringVar(&cfg.Port, "port", "", "Application port")
-	flag.StringVar(&cfg.JSONSchemaPath, "json-schema", "", "Path to JSON schema file")
+// Options indica os grupos de configuração usados por um comando
+type Options int
 
-	flag.Parse()
+const (
+	WithDB       Options = 1 << iota // Conexão com o banco
+	WithSchema                       // Arquivo de schema
+	WithServer                       // Porta, recarga, migração automática e storage
+	WithStorage                      // Só o storage dos uploads (ex.: comandos de importação)
+	NoValidation                     // Não exige os valores obrigatórios (ex.: config print)
+)
+
+// setting é uma opção de configuração: o mesmo valor pode vir do flag, da variável de
+// ambiente ou da chave do arquivo de configuração
+type setting struct {
+	key    string // Chave no arquivo de configuração e no config print
+	flag   string
+	env    string
+	def    string // Valor padrão
+	usage  string
+	groups Options // Grupos em que a opção é registrada
+	secret bool    // Mascarado no config print; aceita <ENV>_FILE e <chave>_file com o valor
 
-	// Aplica fallback: CLI > ENV > Default
-	if cfg.DBHost == "" {
-		cfg.DBHost = getEnv("DB_HOST", "localhost")
+	str     func(*Config) *string
+	boolean func(*Config) *bool
+}
+
+// settings lista as opções na ordem do config print
+var settings = []setting{
+	{key: "db_host", flag: "db-host", env: "DB_HOST", def: "localhost", groups: WithDB,
+		usage: "Host do banco de dados", str: func(c *Config) *string { return &c.DBHost }},
+	{key: "db_port", flag: "db-port", env: "DB_PORT", def: "3306", groups: WithDB,
+		usage: "Porta do banco de dados", str: func(c *Config) *string { return &c.DBPort }},
+	{key: "db_user", flag: "db-user", env: "DB_USER", groups: WithDB,
+		usage: "Usuário do banco de dados (obrigatório)", str: func(c *Config) *string { return &c.DBUser }},
+	{key: "db_psw", flag: "db-psw", env: "DB_PSW", groups: WithDB, secret: true,
+		usage: "Senha do banco de dados", str: func(c *Config) *string { return &c.DBPassword }},
+	{key: "db_name", flag: "db-name", env: "DB_NAME", groups: WithDB,
+		usage: "Nome do banco de dados (obrigatório)", str: func(c *Config) *string { return &c.DBName }},
+	{key: "json_schema", flag: "json-schema", env: "JSON_SCHEMA", groups: WithSchema,
+		usage: "Caminho do schema: .json, .yaml/.yml ou .toml (obrigatório)", str: func(c *Config) *string { return &c.JSONSchemaPath }},
+	{key: "port", flag: "port", env: "PORT", def: "8080", groups: WithServer,
+		usage: "Porta da aplicação", str: func(c *Config) *string { return &c.Port }},
+	{key: "watch", flag: "watch", env: "WATCH", groups: WithServer,
+		usage: "Recarrega schema e templates ao detectar alterações", boolean: func(c *Config) *bool { return &c.Watch }},
+	{key: "auto_migrate", flag: "auto-migrate", env: "AUTO_MIGRATE", groups: WithServer,
+		usage: "Aplica a migração do schema ao iniciar", boolean: func(c *Config) *bool { return &c.AutoMigrate }},
+	{key: "templates_dir", flag: "templates-dir", env: "TEMPLATES_DIR", groups: WithServer,
+		usage: "Diretório com templates que substituem ou complementam os embutidos (ex.: form.html)", str: func(c *Config) *string { return &c.TemplatesDir }},
+	{key: "static_dir", flag: "static-dir", env: "STATIC_DIR", groups: WithServer,
+		usage: "Diretório com arquivos estáticos que substituem os embutidos (ex.: js/main.js)", str: func(c *Config) *string { return &c.StaticDir }},
+	{key: "pagination", flag: "pagination", env: "PAGINATION", def: "offset", groups: WithServer,
+		usage: "Navegação da lista: offset (páginas numeradas) ou keyset (cursor, para tabelas grandes)", str: func(c *Config) *string { return &c.Pagination }},
+	{key: "list_count", flag: "list-count", env: "LIST_COUNT", def: "exact", groups: WithServer,
+		usage: "Contagem de registros no modo keyset e na API: exact, estimate ou none", str: func(c *Config) *string { return &c.ListCount }},
+	{key: "theme_title", flag: "theme-title", env: "THEME_TITLE", groups: WithServer,
+		usage: "Título da página", str: func(c *Config) *string { return &c.ThemeTitle }},
+	{key: "theme_logo", flag: "theme-logo", env: "THEME_LOGO", groups: WithServer,
+		usage: "URL do logo exibido no cabeçalho, ex.: /static/logo.png", str: func(c *Config) *string { return &c.ThemeLogo }},
+	{key: "theme_primary_color", flag: "theme-primary-color", env: "THEME_PRIMARY_COLOR", groups: WithServer,
+		usage: "Cor dos botões, ex.: #0f766e", str: func(c *Config) *string { return &c.ThemePrimaryColor }},
+	{key: "theme_background_color", flag: "theme-background-color", env: "THEME_BACKGROUND_COLOR", groups: WithServer,
+		usage: "Cor de fundo da página", str: func(c *Config) *string { return &c.ThemeBackgroundColor }},
+	{key: "storage", flag: "storage", env: "STORAGE", def: "local", groups: WithServer | WithStorage,
+		usage: "Onde guardar uploads: local ou s3", str: func(c *Config) *string { return &c.Storage }},
+	{key: "upload_dir", flag: "upload-dir", env: "UPLOAD_DIR", def: "uploads", groups: WithServer | WithStorage,
+		usage: "Diretório dos uploads no storage local", str: func(c *Config) *string { return &c.UploadDir }},
+	{key: "s3_endpoint", flag: "s3-endpoint", env: "S3_ENDPOINT", groups: WithServer | WithStorage,
+		usage: "Endpoint S3 ou compatível, ex.: http://localhost:9000", str: func(c *Config) *string { return &c.S3Endpoint }},
+	{key: "s3_bucket", flag: "s3-bucket", env: "S3_BUCKET", groups: WithServer | WithStorage,
+		usage: "Bucket S3", str: func(c *Config) *string { return &c.S3Bucket }},
+	{key: "s3_region", flag: "s3-region", env: "S3_REGION", def: "us-east-1", groups: WithServer | WithStorage,
+		usage: "Região S3", str: func(c *Config) *string { return &c.S3Region }},
+	{key: "s3_access_key", flag: "s3-access-key", env: "S3_ACCESS_KEY", groups: WithServer | WithStorage, secret: true,
+		usage: "Access key S3", str: func(c *Config) *string { return &c.S3AccessKey }},
+	{key: "s3_secret_key", flag: "s3-secret-key", env: "S3_SECRET_KEY", groups: WithServer | WithStorage, secret: true,
+		usage: "Secret key S3", str: func(c *Config) *string { return &c.S3SecretKey }},
+}
+
+// help monta o texto do flag: descrição, padrão e variáveis de ambiente
+func (s setting) help() string {
+	text := s.usage
+	if s.def != "" {
+		text += fmt.Sprintf(" (padrão: %s)", s.def)
 	}
-	if cfg.DBPort == "" {
-		cfg.DBPort = getEnv("DB_PORT", "3306")
+	if s.boolean != nil {
+		return text + fmt.Sprintf(" [%s=true]", s.env)
 	}
-	if cfg.DBUser == "" {
-		cfg.DBUser = getEnv("DB_USER", "")
+	if s.secret {
+		return text + fmt.Sprintf(" [%s ou %s_FILE]", s.env, s.env)
 	}
-	if cfg.DBPassword == "" {
-		cfg.DBPassword = getEnv("DB_PSW", "")
+	return text + fmt.Sprintf(" [%s]", s.env)
+}
+
+// Load registra em fs os flags dos grupos pedidos, faz o parse de args e completa o que
+// não veio pela CLI com as variáveis de ambiente, o arquivo de configuração (perfil
+// escolhido e depois os valores comuns) e os padrões: CLI > ENV > arquivo > padrão
+func Load(fs *flag.FlagSet, args []string, opts Options) (*Config, error) {
+	cfg := &Config{sources: map[string]string{}}
+
+	// Define os flags da CLI
+	active := []setting{}
+	for _, s := range settings {
+		if s.groups&opts == 0 {
+			continue
+		}
+		active = append(active, s)
+		if s.boolean != nil {
+			fs.BoolVar(s.boolean(cfg), s.flag, false, s.help())
+		} else {
+			fs.StringVar(s.str(cfg), s.flag, "", s.help())
+		}
 	}
-	if cfg.DBName == "" {
-		cfg.DBName = getEnv("DB_NAME", "")
+	configPath := fs.String("config", "", "Arquivo de configuração .yaml/.yml ou .toml (padrão: "+strings.Join(defaultConfigFiles, ", ")+", se existir) [CONFIG_FILE]")
+	profile := fs.String("profile", "", "Perfil do arquivo de configuração, ex.: dev, staging, prod [APP_PROFILE]")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
 	}
-	if cfg.Port == "" {
-		cfg.Port = getEnv("PORT", "8080")
+	fromCLI := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { fromCLI[f.Name] = true })
+
+	// Arquivo de configuração
+	cfg.ConfigFile = firstNonEmpty(*configPath, getEnv("CONFIG_FILE", ""), findDefaultConfigFile())
+	cfg.Profile = firstNonEmpty(*profile, getEnv("APP_PROFILE", ""))
+	file, err := loadConfigFile(cfg.ConfigFile, cfg.Profile)
+	if err != nil {
+		return nil, &ValidationError{err.Error()}
+	}
+
+	// Aplica fallback: CLI > ENV > arquivo > padrão
+	for _, s := range active {
+		if fromCLI[s.flag] {
+			cfg.sources[s.key] = "linha de comando (--" + s.flag + ")"
+			continue
+		}
+		value, source, err := s.lookup(file)
+		if err != nil {
+			return nil, &ValidationError{err.Error()}
+		}
+		cfg.sources[s.key] = source
+		if s.boolean == nil {
+			*s.str(cfg) = value
+			continue
+		}
+		if value == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, &ValidationError{fmt.Sprintf("%s deve ser true ou false (%s)", s.key, source)}
+		}
+		*s.boolean(cfg) = b
 	}
-	if cfg.JSONSchemaPath == "" {
-		cfg.JSONSchemaPath = getEnv("JSON_SCHEMA", "")
+
+	if opts&NoValidation != 0 {
+		return cfg, nil
 	}
 
 	// Validações
-	if cfg.DBName == "" {
-		return nil, errors.New("DB_NAME é obrigatória (use --db-name ou variável de ambiente DB_NAME)")
+	if opts&WithDB != 0 {
+		if cfg.DBName == "" {
+			return nil, &ValidationError{"DB_NAME é obrigatória (use --db-name, a variável de ambiente DB_NAME ou db_name no arquivo de configuração)"}
+		}
+		if cfg.DBUser == "" {
+			return nil, &ValidationError{"DB_USER é obrigatória (use --db-user, a variável de ambiente DB_USER ou db_user no arquivo de configuração)"}
+		}
+	}
+	if opts&WithSchema != 0 && cfg.JSONSchemaPath == "" {
+		return nil, &ValidationError{"JSON_SCHEMA é obrigatória (use --json-schema, a variável de ambiente JSON_SCHEMA ou json_schema no arquivo de configuração)"}
 	}
-	if cfg.DBUser == "" {
-		return nil, errors.New("DB_USER é obrigatária (use --db-user ou variável de ambiente DB_USER)")
+	if opts&(WithServer|WithStorage) != 0 && cfg.Storage != "local" && cfg.Storage != "s3" {
+		return nil, &ValidationError{"STORAGE deve ser 'local' ou 's3' (use --storage, a variável de ambiente STORAGE ou storage no arquivo de configuração)"}
 	}
-	if cfg.JSONSchemaPath == "" {
-		return nil, errors.New("JSON_SCHEMA é obrigatária (use --json-schema ou variável de ambiente JSON_SCHEMA)")
+	if opts&WithServer != 0 {
+		for _, dir := range []struct{ name, path string }{{"TEMPLATES_DIR", cfg.TemplatesDir}, {"STATIC_DIR", cfg.StaticDir}} {
+			if info, err := os.Stat(dir.path); dir.path != "" && (err != nil || !info.IsDir()) {
+				return nil, &ValidationError{fmt.Sprintf("%s deve ser um diretório existente: %s", dir.name, dir.path)}
+			}
+		}
+		if cfg.Pagination != "offset" && cfg.Pagination != "keyset" {
+			return nil, &ValidationError{"PAGINATION deve ser 'offset' ou 'keyset' (use --pagination, a variável de ambiente PAGINATION ou pagination no arquivo de configuração)"}
+		}
+		if !slices.Contains(models.CountModes, cfg.ListCount) {
+			return nil, &ValidationError{fmt.Sprintf("LIST_COUNT deve ser %s (use --list-count, a variável de ambiente LIST_COUNT ou list_count no arquivo de configuração)", strings.Join(models.CountModes, ", "))}
+		}
+		for _, color := range []struct{ name, value string }{{"THEME_PRIMARY_COLOR", cfg.ThemePrimaryColor}, {"THEME_BACKGROUND_COLOR", cfg.ThemeBackgroundColor}} {
+			if color.value != "" && !models.IsValidColor(color.value) {
+				return nil, &ValidationError{fmt.Sprintf("%s: cor inválida %q (use #rgb, #rrggbb ou um nome, ex.: teal)", color.name, color.value)}
+			}
+		}
 	}
 
 	return cfg, nil
 }
 
+// lookup busca o valor fora da CLI e descreve de onde ele veio. Opções secretas também
+// podem apontar para um arquivo com o valor (ex.: DB_PSW_FILE=/run/secrets/db_psw).
+func (s setting) lookup(file configFile) (value, source string, err error) {
+	if value, ok := os.LookupEnv(s.env); ok {
+		return value, "env " + s.env, nil
+	}
+	if s.secret {
+		if path, ok := os.LookupEnv(s.env + "_FILE"); ok {
+			value, err := readSecretFile(path)
+			return value, fmt.Sprintf("env %s_FILE (%s)", s.env, path), err
+		}
+	}
+	if entry, ok := file.values[s.key]; ok {
+		return entry.value, file.describe(entry), nil
+	}
+	if s.secret {
+		if entry, ok := file.values[s.key+"_file"]; ok {
+			value, err := readSecretFile(entry.value)
+			return value, fmt.Sprintf("%s, %s_file (%s)", file.describe(entry), s.key, entry.value), err
+		}
+	}
+	if s.def != "" || s.boolean != nil {
+		return s.def, "padrão", nil
+	}
+	return "", "não definido", nil
+}
+
+// readSecretFile lê o valor de um arquivo de segredo, sem a quebra de linha final
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("falha ao ler arquivo de segredo: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Entry é uma opção da configuração efetiva, para exibição (config print)
+type Entry struct {
+	Key    string
+	Value  string
+	Secret bool
+	Source string
+}
+
+// Entries lista as opções carregadas por Load, na ordem de settings, com a origem de cada valor
+func (c *Config) Entries() []Entry {
+	entries := []Entry{}
+	for _, s := range settings {
+		source, ok := c.sources[s.key]
+		if !ok {
+			continue
+		}
+		value := ""
+		if s.boolean != nil {
+			value = strconv.FormatBool(*s.boolean(c))
+		} else {
+			value = *s.str(c)
+		}
+		entries = append(entries, Entry{Key: s.key, Value: value, Secret: s.secret, Source: source})
+	}
+	return entries
+}
+
+// EnvVars lista as variáveis de ambiente lidas pela configuração (para a ajuda da CLI)
+func EnvVars() []string {
+	vars := []string{"CONFIG_FILE", "APP_PROFILE"}
+	for _, s := range settings {
+		vars = append(vars, s.env)
+	}
+	return vars
+}
+
 // getEnv busca uma variável de ambiente ou retorna um valor padrão
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
@@ -76,3 +333,12 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
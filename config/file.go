@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Arquivos de configuração procurados no diretório atual quando --config não é informado
+var defaultConfigFiles = []string{"crud-app.yaml", "crud-app.yml", "crud-app.toml"}
+
+// configFile são os valores do arquivo de configuração já combinados: os do perfil
+// escolhido por cima dos valores comuns (topo do arquivo)
+type configFile struct {
+	path    string
+	profile string
+	values  map[string]fileEntry
+}
+
+// fileEntry é um valor do arquivo e se ele veio do perfil
+type fileEntry struct {
+	value       string
+	fromProfile bool
+}
+
+// describe informa de onde veio o valor (config print)
+func (f configFile) describe(entry fileEntry) string {
+	if entry.fromProfile {
+		return fmt.Sprintf("arquivo %s, perfil %s", f.path, f.profile)
+	}
+	return "arquivo " + f.path
+}
+
+// findDefaultConfigFile retorna o primeiro arquivo padrão existente ("" se nenhum)
+func findDefaultConfigFile() string {
+	for _, name := range defaultConfigFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// loadConfigFile lê o arquivo de configuração (YAML ou TOML, pela extensão). As chaves
+// são as das opções (ex.: db_host, port, watch); a seção "profiles" traz os perfis, cada
+// um com as chaves que mudam em relação ao topo do arquivo:
+//
+//	db_name: crud_app
+//	profiles:
+//	  dev:
+//	    db_user: root
+//	  prod:
+//	    db_host: db.interno
+//	    db_psw_file: /run/secrets/db_psw
+func loadConfigFile(path, profile string) (configFile, error) {
+	file := configFile{path: path, profile: profile, values: map[string]fileEntry{}}
+	if path == "" {
+		if profile != "" {
+			return file, fmt.Errorf("perfil %q informado, mas nenhum arquivo de configuração encontrado (use --config ou crie %s)", profile, defaultConfigFiles[0])
+		}
+		return file, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file, fmt.Errorf("falha ao ler arquivo de configuração: %w", err)
+	}
+
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		_, err = toml.Decode(string(data), &raw)
+	default:
+		return file, fmt.Errorf("%s: formato não suportado (use .yaml, .yml ou .toml)", path)
+	}
+	if err != nil {
+		return file, fmt.Errorf("%s: %w", path, err)
+	}
+
+	profiles := map[string]interface{}{}
+	if section, ok := raw["profiles"]; ok {
+		if profiles, ok = section.(map[string]interface{}); !ok {
+			return file, fmt.Errorf("%s: profiles deve ser uma seção com um perfil por chave", path)
+		}
+		delete(raw, "profiles")
+	}
+	if err := file.merge(raw, false, ""); err != nil {
+		return file, err
+	}
+
+	if profile == "" {
+		return file, nil
+	}
+	section, ok := profiles[profile].(map[string]interface{})
+	if !ok {
+		names := []string{}
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return file, fmt.Errorf("%s: perfil %q não encontrado (o arquivo não define profiles)", path, profile)
+		}
+		return file, fmt.Errorf("%s: perfil %q não encontrado (perfis: %s)", path, profile, strings.Join(names, ", "))
+	}
+	return file, file.merge(section, true, "profiles."+profile+".")
+}
+
+// merge valida as chaves de uma seção e copia os valores (sobrescrevendo os anteriores)
+func (f *configFile) merge(section map[string]interface{}, fromProfile bool, prefix string) error {
+	for key, value := range section {
+		if !knownFileKey(key) {
+			return fmt.Errorf("%s: chave desconhecida %s%s", f.path, prefix, key)
+		}
+		switch value.(type) {
+		case map[string]interface{}, []interface{}, []map[string]interface{}:
+			return fmt.Errorf("%s: %s%s deve ser um valor simples", f.path, prefix, key)
+		case nil:
+			continue
+		}
+		f.values[key] = fileEntry{value: fmt.Sprint(value), fromProfile: fromProfile}
+	}
+	return nil
+}
+
+// knownFileKey indica se a chave é uma opção (ou <opção>_file, para as secretas)
+func knownFileKey(key string) bool {
+	for _, s := range settings {
+		if key == s.key || s.secret && key == s.key+"_file" {
+			return true
+		}
+	}
+	return false
+}
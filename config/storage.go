@@ -0,0 +1,19 @@
+package config
+
+import (
+	"go-crud-generator/storage"
+)
+
+// InitStorage cria o backend de armazenamento dos uploads conforme a configuração
+func InitStorage(cfg *Config) (storage.Storage, error) {
+	if cfg.Storage == "s3" {
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	}
+	return storage.NewLocalStorage(cfg.UploadDir)
+}
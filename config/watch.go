@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// WatchFiles verifica periodicamente a data de modificação e o tamanho dos arquivos e
+// chama onChange quando algum deles muda. Roda até stop ser fechado.
+// paths é chamada a cada verificação, então arquivos criados depois (um template novo,
+// por exemplo) passam a ser observados, e um arquivo que entra ou sai da lista conta
+// como mudança.
+// Usa polling (e não inotify) para funcionar igual em Linux, macOS, Windows e volumes montados.
+func WatchFiles(paths func() []string, interval time.Duration, stop <-chan struct{}, onChange func()) {
+	last := fileStamps(paths())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			current := fileStamps(paths())
+			if current != last {
+				last = current
+				onChange()
+			}
+		}
+	}
+}
+
+// fileStamps resume o estado dos arquivos em uma string comparável
+func fileStamps(paths []string) string {
+	stamp := ""
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			stamp += path + ":ausente;"
+			continue
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return stamp
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Um arquivo criado depois do início passa a ser observado: a criação e as edições
+// seguintes disparam onChange
+func TestWatchFilesPicksUpNewFiles(t *testing.T) {
+	dir := t.TempDir()
+	started := make(chan struct{})
+	var once sync.Once
+	list := func() []string {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.html"))
+		once.Do(func() { close(started) }) // A primeira leitura acontece sem o arquivo
+		return files
+	}
+	changes := make(chan struct{}, 10)
+	stop := make(chan struct{})
+	defer close(stop)
+	go WatchFiles(list, 10*time.Millisecond, stop, func() { changes <- struct{}{} })
+
+	wait := func(what string) {
+		t.Helper()
+		select {
+		case <-changes:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s não foi percebido", what)
+		}
+	}
+
+	path := filepath.Join(dir, "novo.html")
+	<-started
+	if err := os.WriteFile(path, []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wait("o template criado")
+
+	if err := os.WriteFile(path, []byte("ab"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wait("a edição do template criado")
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	wait("a remoção do template")
+}
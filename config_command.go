@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"go-crud-generator/config"
+)
+
+// runConfig implementa "crud-app config print": mostra a configuração efetiva (com as
+// mesmas opções, variáveis de ambiente e arquivo dos outros comandos) e a origem de cada valor
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		if len(args) > 0 && !isHelpArg(args[0]) {
+			fmt.Fprintf(os.Stderr, "❌ Subcomando desconhecido: config %s\n\n", args[0])
+		}
+		fmt.Fprintln(os.Stderr, "Uso:\n  ./crud-app config print [--config arquivo] [--profile nome] [opções]")
+		if len(args) > 0 && isHelpArg(args[0]) {
+			return 0
+		}
+		return 2
+	}
+
+	fs := newCommandFlags("config print", "[--config arquivo] [--profile nome] [opções]",
+		"Mostra a configuração efetiva e a origem de cada valor (CLI > ENV > arquivo > padrão).\n"+
+			"Senhas e chaves aparecem mascaradas.")
+	cfg, code := loadCommandConfig(fs, args[1:], config.WithDB|config.WithSchema|config.WithServer|config.NoValidation)
+	if cfg == nil {
+		return code
+	}
+
+	switch {
+	case cfg.ConfigFile == "":
+		fmt.Println("Arquivo de configuração: (nenhum)")
+	case cfg.Profile == "":
+		fmt.Printf("Arquivo de configuração: %s\n", cfg.ConfigFile)
+	default:
+		fmt.Printf("Arquivo de configuração: %s (perfil %s)\n", cfg.ConfigFile, cfg.Profile)
+	}
+	fmt.Println()
+	// Colunas alinhadas pelo maior valor de cada uma (ex.: theme_background_color)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, entry := range cfg.Entries() {
+		value := entry.Value
+		if entry.Secret {
+			value = maskPassword(value)
+		} else if value == "" {
+			value = "(vazio)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", entry.Key, value, entry.Source)
+	}
+	tw.Flush()
+	return 0
+}
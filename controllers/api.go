@@ -0,0 +1,363 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-crud-generator/models"
+	"go-crud-generator/validators"
+)
+
+// apiPage é a resposta de /api/records
+type apiPage struct {
+	Data           []map[string]interface{} `json:"data"`
+	NextCursor     string                   `json:"next_cursor,omitempty"` // Passe em ?after= para a próxima página
+	PrevCursor     string                   `json:"prev_cursor,omitempty"` // Passe em ?before= para a anterior
+	Total          *int                     `json:"total,omitempty"`       // Ausente com ?count=none
+	TotalEstimated bool                     `json:"total_estimated,omitempty"`
+}
+
+// handleAPIList devolve uma página de registros em JSON, paginada por cursor
+// (?after=/?before=), com a mesma busca e ordenação da lista. ?count= escolhe a contagem:
+// exact, estimate ou none (padrão: o da configuração).
+func (c *CRUDController) handleAPIList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	query := r.URL.Query()
+	params := parseListParams(query, c.schema)
+	if sort := query.Get("sort"); sort != "" && params.Sort == "" {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("não é possível ordenar por %q (campos: %s)", sort, strings.Join(sortableFields(c.schema), ", ")))
+		return
+	}
+	count := c.list.Count
+	if value := query.Get("count"); value != "" {
+		if !slices.Contains(models.CountModes, value) {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("count deve ser %s", strings.Join(models.CountModes, ", ")))
+			return
+		}
+		count = models.CountMode(value)
+	}
+
+	page, err := c.repo.FindPage(params.pageOptions(count))
+	if errors.Is(err, models.ErrInvalidCursor) {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar dados: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "Erro ao buscar dados")
+		return
+	}
+
+	for _, row := range page.Rows {
+		validators.FormatSingleDataBySchema(c.schema, row)
+	}
+	response := apiPage{Data: page.Rows, NextCursor: page.Next, PrevCursor: page.Prev, TotalEstimated: page.Estimated}
+	if page.Total >= 0 {
+		response.Total = &page.Total
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Tamanho máximo do corpo JSON de /api/record
+const maxJSONBody = 1 << 20
+
+// apiRecord é a resposta de /api/record: o registro no formato do formulário (o mesmo de
+// /get) e no formato exibido na lista
+type apiRecord struct {
+	Data    map[string]interface{} `json:"data"`
+	Display map[string]interface{} `json:"display"`
+}
+
+// apiErrors é a resposta de erro com as mensagens de validação por campo
+type apiErrors struct {
+	Error  string            `json:"error"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// handleAPIRecord altera campos de um registro (PATCH com corpo JSON {"campo": valor}),
+// como na edição de uma célula da lista: só os campos enviados mudam e passam pela mesma
+// validação do formulário; null ou "" limpa o campo. Com lock otimista, a versão carregada
+// vai no mesmo objeto (obrigatória; sem ela, 400) e um registro alterado por outro usuário
+// devolve 409.
+func (c *CRUDController) handleAPIRecord(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	key, err := c.parseKey(r.URL.Query())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var body map[string]interface{}
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBody)
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber() // Números chegam como texto: bigint e decimal não passam por float64
+	if err := dec.Decode(&body); err != nil || body == nil {
+		writeJSONError(w, http.StatusBadRequest, "Corpo deve ser um objeto JSON")
+		return
+	}
+
+	data := map[string]interface{}{}
+	validationErrors := map[string]string{}
+	fields := 0
+	for name, raw := range body {
+		if name == c.schema.VersionField {
+			version, err := strconv.Atoi(strings.Join(jsonFormValues(raw), ""))
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, "Versão do registro inválida")
+				return
+			}
+			data[name] = version
+			continue
+		}
+		field, ok := c.schema.FieldByName(name)
+		if !ok || !isBulkEditable(field) {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("o campo %q não pode ser alterado por aqui", name))
+			return
+		}
+		fields++
+		value, msg := validators.ValidateField(field, jsonFormValues(raw))
+		if msg != "" {
+			validationErrors[name] = msg
+			continue
+		}
+		data[name] = value
+	}
+	if fields == 0 {
+		writeJSONError(w, http.StatusBadRequest, "Nenhum campo para alterar")
+		return
+	}
+	if versionField := c.schema.VersionField; versionField != "" && data[versionField] == nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Versão do registro obrigatória (%q)", versionField))
+		return
+	}
+	if len(c.schema.Rules) > 0 && len(validationErrors) == 0 {
+		current, err := c.repo.FindByID(key)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "Registro não encontrado")
+			return
+		}
+		if err != nil {
+			log.Printf("Erro ao buscar por ID: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, "Erro ao buscar registro")
+			return
+		}
+		validators.CheckRules(c.schema, mergeRecord(current, data), data, validationErrors)
+	}
+	if len(validationErrors) > 0 {
+		writeValidationErrors(w, validationErrors)
+		return
+	}
+
+	if err := c.repo.Update(key, data); err != nil {
+		var conflict *models.ConflictError
+		if errors.As(err, &conflict) {
+			validators.FormatSingleDataBySchema(c.schema, conflict.Current)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Este registro foi alterado por outro usuário. Recarregue a página.",
+				"current": conflict.Current,
+			})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "Registro não encontrado")
+			return
+		}
+		if c.duplicateFieldError(err, validationErrors) {
+			writeValidationErrors(w, validationErrors)
+			return
+		}
+		log.Printf("Erro ao atualizar registro: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "Erro interno ao atualizar")
+		return
+	}
+
+	record, err := c.repo.FindByID(key)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSONError(w, http.StatusNotFound, "Registro não encontrado")
+		return
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar por ID: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "Erro ao buscar registro")
+		return
+	}
+
+	display := make(map[string]interface{}, len(record))
+	for k, v := range record {
+		display[k] = v
+	}
+	validators.FormatSingleDataBySchema(c.schema, record)
+	validators.FormatDataBySchema(c.schema, []map[string]interface{}{display})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(apiRecord{Data: record, Display: display})
+}
+
+// jsonFormValues converte um valor JSON para os valores de formulário que a validação
+// espera: null vira nenhum valor, true/false viram "1"/"0", números seguem como foram
+// escritos (decodificados com UseNumber) e objetos/listas (campos json) voltam ao texto JSON
+func jsonFormValues(raw interface{}) []string {
+	switch v := raw.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case bool:
+		if v {
+			return []string{"1"}
+		}
+		return []string{"0"}
+	case json.Number:
+		return []string{v.String()}
+	}
+	encoded, _ := json.Marshal(raw)
+	return []string{string(encoded)}
+}
+
+// handleAPIValidation devolve o manifesto de validação do schema (ver validators.Manifest),
+// que o main.js usa para validar o formulário com as mesmas regras do servidor
+func (c *CRUDController) handleAPIValidation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+	manifest := validators.BuildManifest(c.schema)
+
+	// Colunas de chaves estrangeiras também são conferidas no banco (ver handleAPIValidate)
+	relations, err := c.repo.ParentRelations()
+	if err != nil {
+		log.Printf("⚠️ Não foi possível ler as chaves estrangeiras: %v", err)
+	}
+	for _, rel := range relations {
+		for i := range manifest.Fields {
+			if slices.Contains(rel.RefColumns, manifest.Fields[i].Name) {
+				manifest.Fields[i].Remote = true
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache") // O schema pode mudar com --watch
+	json.NewEncoder(w).Encode(manifest)
+}
+
+// fieldCheck é a resposta de /api/validate
+type fieldCheck struct {
+	Valid  bool              `json:"valid"`
+	Errors map[string]string `json:"errors"`
+}
+
+// handleAPIValidate valida os campos enviados sem gravar nada, para o formulário avisar
+// antes do envio (ex.: "Valor já cadastrado" ao sair do campo). O corpo é um formulário
+// parcial, validado como no PATCH (validators.ValidatePartial), mais as conferências que
+// dependem do banco: valor repetido em campo unique ou na chave e chave estrangeira que
+// aponta para um registro inexistente. Na edição, a chave do registro vai na query string e
+// ele mesmo não conta como repetido.
+func (c *CRUDController) handleAPIValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Erro ao parsear formulário")
+		return
+	}
+
+	var except models.Key
+	if len(r.URL.Query()) > 0 {
+		key, err := c.parseKey(r.URL.Query())
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		except = key
+	}
+
+	data, validationErrors := validators.ValidatePartial(r.PostForm, c.schema)
+	if err := c.checkDatabase(data, except, validationErrors); err != nil {
+		log.Printf("Erro ao validar no banco: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "Erro ao validar no banco")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fieldCheck{Valid: len(validationErrors) == 0, Errors: validationErrors})
+}
+
+// checkDatabase confere no banco os valores já validados de data: repetição em campos
+// unique e na chave simples (exceto no registro except) e existência do registro apontado
+// pelas chaves estrangeiras cujas colunas vieram todas preenchidas
+func (c *CRUDController) checkDatabase(data map[string]interface{}, except models.Key, validationErrors map[string]string) error {
+	singleKey := len(c.schema.PrimaryKeyFields()) == 1
+	for _, field := range c.schema.Fields {
+		value := data[field.Name]
+		if value == nil || !(field.Unique || field.PrimaryKey && singleKey) {
+			continue
+		}
+		taken, err := c.repo.ValueTaken(field.Name, value, except)
+		if err != nil {
+			return err
+		}
+		if taken {
+			validationErrors[field.Name] = duplicateMessage
+		}
+	}
+
+	relations, err := c.repo.ParentRelations()
+	if err != nil {
+		return err
+	}
+	for _, rel := range relations {
+		complete := true
+		for _, column := range rel.RefColumns {
+			_, failed := validationErrors[column]
+			complete = complete && data[column] != nil && !failed
+		}
+		if !complete {
+			continue
+		}
+		exists, err := c.repo.ReferenceExists(rel, data)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			for _, column := range rel.RefColumns {
+				validationErrors[column] = fmt.Sprintf("Registro não encontrado em %s", rel.Table)
+			}
+		}
+	}
+	return nil
+}
+
+// writeValidationErrors responde 422 com os erros de validação por campo
+func writeValidationErrors(w http.ResponseWriter, validationErrors map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(apiErrors{Error: "Dados inválidos", Errors: validationErrors})
+}
+
+// writeJSONError responde {"error": mensagem} com o status informado
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Os valores do corpo do PATCH chegam à validação como o cliente os escreveu
+func TestJSONFormValues(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{`null`, nil},
+		{`"abc"`, []string{"abc"}},
+		{`true`, []string{"1"}},
+		{`false`, []string{"0"}},
+		{`9007199254740993`, []string{"9007199254740993"}}, // Não cabe em float64
+		{`12345678901234.5678`, []string{"12345678901234.5678"}},
+		{`1e3`, []string{"1e3"}},
+		{`{"a": 9007199254740993, "b": [1, 2]}`, []string{`{"a":9007199254740993,"b":[1,2]}`}},
+	}
+	for _, tt := range tests {
+		dec := json.NewDecoder(strings.NewReader(tt.body))
+		dec.UseNumber()
+		var raw interface{}
+		if err := dec.Decode(&raw); err != nil {
+			t.Fatal(err)
+		}
+		if got := jsonFormValues(raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("jsonFormValues(%s) = %q, esperava %q", tt.body, got, tt.want)
+		}
+	}
+}
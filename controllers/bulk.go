@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go-crud-generator/export"
+	"go-crud-generator/models"
+	"go-crud-generator/validators"
+)
+
+// Máximo de registros por ação em massa (a seleção vem de uma página da lista)
+const maxBulkRecords = 1000
+
+// Cookie com a mensagem exibida depois do redirecionamento (ex.: resumo de uma ação em massa)
+const flashCookie = "flash"
+
+// handleBulk executa uma ação nos registros marcados na lista (campos "keys", cada um
+// com a chave como query string): delete, export (format csv ou json) ou edit (grava
+// "value" no campo "field" de todos). Exclusão e edição acontecem numa única transação.
+func (c *CRUDController) handleBulk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao parsear formulário", http.StatusBadRequest)
+		return
+	}
+
+	back := c.formListParams(r.PostForm).url()
+	keys, err := c.parseKeys(r.PostForm["keys"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(keys) == 0 {
+		setFlash(w, "error", "Nenhum registro selecionado.")
+		http.Redirect(w, r, back, http.StatusFound)
+		return
+	}
+
+	switch action := r.PostForm.Get("action"); action {
+	case "delete":
+		deleted, err := c.repo.DeleteMany(keys)
+		if err != nil {
+			log.Printf("Erro ao excluir registros: %v", err)
+			http.Error(w, "Erro ao excluir registros", http.StatusInternalServerError)
+			return
+		}
+		for _, record := range deleted {
+			c.deleteFiles(c.recordFiles(record))
+		}
+		setFlash(w, "success", fmt.Sprintf("%d registro(s) excluído(s).%s", len(deleted), missingNote(len(keys), len(deleted))))
+
+	case "export":
+		c.exportKeys(w, keys, r.PostForm.Get("format"))
+		return
+
+	case "edit":
+		field, ok := c.schema.FieldByName(r.PostForm.Get("field"))
+		if !ok || !isBulkEditable(field) {
+			http.Error(w, "Campo inválido para edição em massa", http.StatusBadRequest)
+			return
+		}
+		value, msg := validators.ValidateField(field, r.PostForm["value"])
+		if msg != "" {
+			setFlash(w, "error", fmt.Sprintf("%s: %s. Nenhum registro foi alterado.", field.DisplayLabel(), msg))
+			break
+		}
+		msg, err := c.bulkRuleError(keys, field.Name, value)
+		if err != nil {
+			log.Printf("Erro ao avaliar regras dos registros: %v", err)
+			http.Error(w, "Erro ao atualizar registros", http.StatusInternalServerError)
+			return
+		}
+		if msg != "" {
+			setFlash(w, "error", msg+" Nenhum registro foi alterado.")
+			break
+		}
+
+		updated, err := c.repo.UpdateMany(keys, field.Name, value)
+		if errors.Is(err, models.ErrDuplicate) {
+			setFlash(w, "error", fmt.Sprintf("%s: valor já cadastrado (o campo não pode repetir). Nenhum registro foi alterado.", field.DisplayLabel()))
+			break
+		}
+		if err != nil {
+			log.Printf("Erro ao atualizar registros: %v", err)
+			http.Error(w, "Erro ao atualizar registros", http.StatusInternalServerError)
+			return
+		}
+		display := "(vazio)"
+		if value != nil {
+			display = `"` + validators.FormatDisplayValue(field, value) + `"`
+		}
+		setFlash(w, "success", fmt.Sprintf("%d registro(s) atualizado(s): %s = %s.%s",
+			updated, field.DisplayLabel(), display, missingNote(len(keys), updated)))
+
+	default:
+		http.Error(w, fmt.Sprintf("Ação desconhecida: %q", action), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, back, http.StatusFound)
+}
+
+// bulkRuleError avalia as regras entre campos em cada registro selecionado com o novo valor
+// e descreve uma regra violada (vazio se todas valem)
+func (c *CRUDController) bulkRuleError(keys []models.Key, name string, value interface{}) (string, error) {
+	if len(c.schema.Rules) == 0 {
+		return "", nil
+	}
+	changes := map[string]interface{}{name: value}
+	violated, message := 0, ""
+	err := c.repo.ForEachKey(keys, func(row map[string]interface{}) error {
+		ruleErrors := map[string]string{}
+		validators.CheckRules(c.schema, mergeRecord(row, changes), changes, ruleErrors)
+		for _, msg := range ruleErrors {
+			if message == "" || msg < message {
+				message = msg
+			}
+		}
+		if len(ruleErrors) > 0 {
+			violated++
+		}
+		return nil
+	})
+	if err != nil || violated == 0 {
+		return "", err
+	}
+	return fmt.Sprintf("%d registro(s) violariam a regra: %s.", violated, strings.TrimSuffix(message, ".")), nil
+}
+
+// exportKeys baixa os registros selecionados em CSV ou JSON (o formato de "crud-app export")
+func (c *CRUDController) exportKeys(w http.ResponseWriter, keys []models.Key, format string) {
+	write := export.CSV
+	contentType := "text/csv; charset=utf-8"
+	switch format {
+	case "", "csv":
+		format = "csv"
+	case "json":
+		write = export.JSON
+		contentType = "application/json"
+	default:
+		http.Error(w, "Formato inválido (use csv ou json)", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", c.schema.TableName+"."+format))
+	each := func(fn func(row map[string]interface{}) error) error {
+		return c.repo.ForEachKey(keys, fn)
+	}
+	if _, err := write(w, c.schema, each); err != nil {
+		// O cabeçalho já foi enviado; resta registrar a falha
+		log.Printf("Erro ao exportar registros: %v", err)
+	}
+}
+
+// parseKeys lê as chaves dos registros selecionados (cada uma como query string, ex.: "id=5")
+func (c *CRUDController) parseKeys(values []string) ([]models.Key, error) {
+	if len(values) > maxBulkRecords {
+		return nil, fmt.Errorf("selecione no máximo %d registros", maxBulkRecords)
+	}
+	keys := []models.Key{}
+	for _, value := range values {
+		query, err := url.ParseQuery(value)
+		if err != nil {
+			return nil, fmt.Errorf("chave inválida: %q", value)
+		}
+		key, err := c.parseKey(query)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// isBulkEditable indica se o campo pode ser alterado fora do formulário, na edição em
+// massa e na edição de uma célula da lista: chaves e arquivos não
+func isBulkEditable(field models.Field) bool {
+	return !field.PrimaryKey && !field.IsUpload()
+}
+
+// bulkFields lista os campos que podem ser alterados em massa
+func bulkFields(schema *models.Schema) []models.Field {
+	fields := []models.Field{}
+	for _, field := range schema.Fields {
+		if isBulkEditable(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// missingNote avisa quando parte dos registros selecionados já não existia
+func missingNote(selected, found int) string {
+	if missing := selected - found; missing > 0 {
+		return fmt.Sprintf(" %d registro(s) selecionado(s) não existia(m) mais.", missing)
+	}
+	return ""
+}
+
+// setFlash guarda a mensagem ("success" ou "error") para a próxima página exibida
+func setFlash(w http.ResponseWriter, kind, message string) {
+	value := url.Values{"kind": {kind}, "message": {message}}.Encode()
+	http.SetCookie(w, &http.Cookie{Name: flashCookie, Value: value, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+}
+
+// readFlash lê e apaga a mensagem guardada por setFlash
+func readFlash(w http.ResponseWriter, r *http.Request, data *TemplateData) {
+	cookie, err := r.Cookie(flashCookie)
+	if err != nil {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: flashCookie, Path: "/", MaxAge: -1})
+
+	values, err := url.ParseQuery(cookie.Value)
+	if err != nil {
+		return
+	}
+	if values.Get("kind") == "error" {
+		data.ErrorMessage = values.Get("message")
+	} else {
+		data.SuccessMessage = values.Get("message")
+	}
+}
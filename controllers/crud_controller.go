@@ -1,66 +1,107 @@
 package controllers
 
 import (
-	"html/template"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
 	"log"
-	"math"
 	"net/http"
-	"go-crud-generator/models"
-	"go-crud-generator/validators"
+	"net/url"
 	"strconv"
+	"sync/atomic"
 	"time"
-)
 
-const defaultPageLimit = 10
+	"go-crud-generator/models"
+	"go-crud-generator/storage"
+	"go-crud-generator/validators"
+)
 
 // CRUDController gerencia as rotas e handlers do CRUD
 type CRUDController struct {
-	repo   *models.DynamicRepository
-	schema *models.Schema
-	tmpl   *template.Template
+	repo    *models.DynamicRepository
+	schema  *models.Schema
+	tmpl    *template.Template
+	storage storage.Storage // Arquivos dos campos file/image
+	theme   models.Theme    // Tema da configuração (o "theme" do schema tem precedência)
+	list    ListConfig      // Navegação da lista (numerada ou por cursor)
+
+	// live aponta para a versão do controller que atende as requisições; Reload troca a
+	// versão inteira de uma vez, então cada requisição vê schema, repo e template coerentes
+	live *atomic.Pointer[CRUDController]
 }
 
 // NewCRUDController cria uma nova instância do controller
-func NewCRUDController(repo *models.DynamicRepository, schema *models.Schema, tmpl *template.Template) *CRUDController {
-	return &CRUDController{
-		repo:   repo,
-		schema: schema,
-		tmpl:   tmpl,
+func NewCRUDController(repo *models.DynamicRepository, schema *models.Schema, tmpl *template.Template, store storage.Storage, theme models.Theme, list ListConfig) *CRUDController {
+	c := &CRUDController{
+		repo:    repo,
+		schema:  schema,
+		tmpl:    tmpl,
+		storage: store,
+		theme:   theme,
+		list:    list,
+		live:    &atomic.Pointer[CRUDController]{},
 	}
+	c.live.Store(c)
+	return c
+}
+
+// Reload troca atomicamente o schema, o repositório e o template usados pelas próximas
+// requisições (requisições em andamento terminam com a versão anterior)
+func (c *CRUDController) Reload(repo *models.DynamicRepository, schema *models.Schema, tmpl *template.Template) {
+	c.live.Store(&CRUDController{
+		repo:    repo,
+		schema:  schema,
+		tmpl:    tmpl,
+		storage: c.storage,
+		theme:   c.theme,
+		list:    c.list,
+		live:    c.live,
+	})
 }
 
 // RegisterRoutes registra as rotas no mux
 func (c *CRUDController) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/", c.handleList)
-	mux.HandleFunc("/create", c.handleCreate)
-	mux.HandleFunc("/update", c.handleUpdate) // Usará /update?id=...
-	mux.HandleFunc("/delete", c.handleDelete) // Usará /delete?id=...
-	mux.HandleFunc("/get", c.handleGetByID)   // Rota AJAX para editar
+	mux.HandleFunc("/", c.dispatch((*CRUDController).handleList))
+	mux.HandleFunc("/create", c.dispatch((*CRUDController).handleCreate))
+	mux.HandleFunc("/update", c.dispatch((*CRUDController).handleUpdate))                // Usará /update?id=...
+	mux.HandleFunc("/delete", c.dispatch((*CRUDController).handleDelete))                // Usará /delete?id=...
+	mux.HandleFunc("/get", c.dispatch((*CRUDController).handleGetByID))                  // Rota AJAX para editar
+	mux.HandleFunc("/view", c.dispatch((*CRUDController).handleView))                    // Página de um registro
+	mux.HandleFunc("/files/", c.dispatch((*CRUDController).handleFile))                  // Arquivos dos campos file/image
+	mux.HandleFunc("/bulk", c.dispatch((*CRUDController).handleBulk))                    // Ações nos registros selecionados
+	mux.HandleFunc("/api/records", c.dispatch((*CRUDController).handleAPIList))          // Lista JSON paginada por cursor
+	mux.HandleFunc("/api/record", c.dispatch((*CRUDController).handleAPIRecord))         // PATCH de campos de um registro (edição na lista)
+	mux.HandleFunc("/api/validation", c.dispatch((*CRUDController).handleAPIValidation)) // Manifesto de validação do formulário
+	mux.HandleFunc("/api/validate", c.dispatch((*CRUDController).handleAPIValidate))     // Validação de campos no servidor, sem gravar
+}
+
+// dispatch encaminha a requisição para a versão atual do controller
+func (c *CRUDController) dispatch(handler func(*CRUDController, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler(c.live.Load(), w, r)
+	}
 }
 
 // TemplateData é a estrutura de dados passada para o template HTML
 type TemplateData struct {
-	Schema       *models.Schema
-	Data         []map[string]interface{}
-	Errors       map[string]string
-	FormData     map[string]string // Para repopular o form em caso de erro
-	SearchTerm   string
-	Pagination   Pagination
-	CurrentTime  int64 // Para cache-busting de estáticos
+	Schema         *models.Schema
+	Data           []map[string]interface{}
+	Errors         map[string]string
+	FormData       map[string]string // Para repopular o form em caso de erro
+	SearchTerm     string
+	Pagination     Pagination
+	CurrentTime    int64 // Para cache-busting de estáticos
 	SuccessMessage string
-    SchemaColspan int // <- ADICIONE ESTA LINHA
-}
-
-// Pagination contém dados para a paginação
-type Pagination struct {
-	CurrentPage int
-	TotalPages  int
-	TotalRecords int
-	HasPrev     bool
-	HasNext     bool
-	PrevPage    int
-	NextPage    int
+	ErrorMessage   string              // Mensagens exibidas depois de redirecionar (ver setFlash)
+	SchemaColspan  int                 // <- ADICIONE ESTA LINHA
+	EditKey        string              // Chave (query string) do registro em edição quando o formulário volta com erros
+	EditURL        template.URL        // Ação do formulário nesse caso: /update?<chave>
+	Theme          models.Theme        // Tema do schema completado pelo da configuração
+	SortLinks      map[string]SortLink // Links dos cabeçalhos das colunas ordenáveis
+	BulkFields     []models.Field      // Campos oferecidos na edição em massa
+	ListState      string              // Estado da lista (query string) enviado pelos formulários
 }
 
 // handleList exibe a página principal com a lista e o formulário
@@ -70,43 +111,75 @@ func (c *CRUDController) handleList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pageStr := r.URL.Query().Get("page")
-	search := r.URL.Query().Get("search")
-	page, _ := strconv.Atoi(pageStr)
-	if page <= 0 {
-		page = 1
-	}
-
-	data, totalRecords, err := c.repo.FindAll(page, defaultPageLimit, search)
+	templateData, err := c.listData(parseListParams(r.URL.Query(), c.schema))
 	if err != nil {
 		log.Printf("Erro ao buscar dados: %v", err)
 		http.Error(w, "Erro ao buscar dados", http.StatusInternalServerError)
 		return
 	}
+	readFlash(w, r, &templateData)
+
+	c.renderTemplate(w, templateData)
+}
+
+// listData busca a página da lista e monta os dados do template comuns a todas as
+// renderizações (lista, paginação, busca e ordenação). Uma página além da última
+// (ex.: depois de excluir o último registro dela) mostra a última.
+func (c *CRUDController) listData(params listParams) (TemplateData, error) {
+	if c.list.Keyset {
+		return c.keysetListData(params)
+	}
+
+	data, totalRecords, err := c.repo.FindAll(params.options())
+	if err != nil {
+		return TemplateData{}, err
+	}
+	if lastPage := (totalRecords + params.PerPage - 1) / params.PerPage; params.Page > lastPage && lastPage > 0 {
+		params.Page = lastPage
+		if data, totalRecords, err = c.repo.FindAll(params.options()); err != nil {
+			return TemplateData{}, err
+		}
+	}
+
+	return c.pageData(params, data, newPagination(params, totalRecords, len(data))), nil
+}
 
-	totalPages := int(math.Ceil(float64(totalRecords) / float64(defaultPageLimit)))
-	pagination := Pagination{
-		CurrentPage: page,
-		TotalPages:  totalPages,
-		TotalRecords: totalRecords,
-		HasPrev:     page > 1,
-		PrevPage:    page - 1,
-		HasNext:     page < totalPages,
-		NextPage:    page + 1,
+// keysetListData é o listData do modo cursor. Um cursor inválido (ex.: de um link antigo,
+// de antes de mudar a ordenação) mostra a primeira página.
+func (c *CRUDController) keysetListData(params listParams) (TemplateData, error) {
+	page, err := c.repo.FindPage(params.pageOptions(c.list.Count))
+	if errors.Is(err, models.ErrInvalidCursor) {
+		params = params.withPage(1)
+		page, err = c.repo.FindPage(params.pageOptions(c.list.Count))
+	}
+	if err != nil {
+		return TemplateData{}, err
 	}
+	return c.pageData(params, page.Rows, newKeysetPagination(params, page)), nil
+}
 
+// pageData monta os dados do template para os registros da página
+func (c *CRUDController) pageData(params listParams, data []map[string]interface{}, pagination Pagination) TemplateData {
 	validators.FormatDataBySchema(c.schema, data)
-	
-	templateData := TemplateData{
-		Schema:     c.schema,
-		Data:       data,
-		SearchTerm: search,
-		Pagination: pagination,
-		CurrentTime: time.Now().Unix(),
-		SchemaColspan: len(c.schema.Fields) + 1,
-	}
-	
-	c.renderTemplate(w, templateData)
+
+	return TemplateData{
+		Schema:        c.schema,
+		Data:          data,
+		SearchTerm:    params.Search,
+		Pagination:    pagination,
+		SortLinks:     sortLinks(params, c.schema),
+		BulkFields:    bulkFields(c.schema),
+		ListState:     params.values().Encode(),
+		CurrentTime:   time.Now().Unix(),
+		SchemaColspan: len(c.schema.Fields) + 2, // Seleção e ações
+	}
+}
+
+// formListParams lê o estado da lista enviado no campo oculto do formulário: a página
+// para onde voltar depois de salvar ou onde exibir os erros
+func (c *CRUDController) formListParams(form url.Values) listParams {
+	state, _ := url.ParseQuery(form.Get(listStateField))
+	return parseListParams(state, c.schema)
 }
 
 // handleCreate processa a submissão do formulário de criação
@@ -116,13 +189,14 @@ func (c *CRUDController) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := r.ParseForm(); err != nil {
+	if err := c.parseRequestForm(w, r); err != nil {
 		http.Error(w, "Erro ao parsear formulário", http.StatusBadRequest)
 		return
 	}
-	
+
 	// Validar e converter dados
 	data, validationErrors := validators.ValidateData(r.PostForm, c.schema)
+	uploads := c.collectUploads(r, nil, data, validationErrors)
 
 	if len(validationErrors) > 0 {
 		// Recarregar a página com erros
@@ -130,59 +204,148 @@ func (c *CRUDController) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	stored, err := c.storeUploads(uploads, data)
+	if err != nil {
+		log.Printf("Erro ao gravar arquivos: %v", err)
+		validationErrors["_form"] = "Erro interno ao salvar os arquivos enviados."
+		c.reloadPageWithErrors(w, r, validationErrors, r.PostForm)
+		return
+	}
+
 	// Inserir no banco
-	_, err := c.repo.Create(data)
+	_, err = c.repo.Create(data)
 	if err != nil {
+		c.deleteFiles(stored)
+		if c.duplicateFieldError(err, validationErrors) {
+			c.reloadPageWithErrors(w, r, validationErrors, r.PostForm)
+			return
+		}
 		log.Printf("Erro ao criar registro: %v", err)
 		validationErrors["_form"] = "Erro interno ao salvar. Verifique se os dados estão corretos."
 		c.reloadPageWithErrors(w, r, validationErrors, r.PostForm)
 		return
 	}
 
-	// Redireciona para a home (pode adicionar ?success=true)
-	http.Redirect(w, r, "/", http.StatusFound)
+	// Volta para a lista na página de onde o formulário foi enviado
+	http.Redirect(w, r, c.formListParams(r.PostForm).url(), http.StatusFound)
 }
 
-// handleUpdate processa a submissão do formulário de edição
+// handleUpdate processa a submissão do formulário de edição (POST ou PATCH, com o mesmo
+// corpo). Só os campos enviados são alterados: um campo ausente mantém o valor atual e um
+// campo enviado vazio é limpo (ver validators.ValidatePartial).
 func (c *CRUDController) handleUpdate(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+	if r.Method != http.MethodPost && r.Method != http.MethodPatch {
 		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
 		return
 	}
 
-	if err := r.ParseForm(); err != nil {
+	if err := c.parseRequestForm(w, r); err != nil {
 		http.Error(w, "Erro ao parsear formulário", http.StatusBadRequest)
 		return
 	}
 
-	id := r.FormValue("id")
-	if id == "" {
-		http.Error(w, "ID ausente", http.StatusBadRequest)
+	key, err := c.parseKey(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	data, validationErrors := validators.ValidateData(r.PostForm, c.schema)
+	// Registro atual: necessário para manter/substituir os arquivos já enviados e para
+	// avaliar as regras entre campos com os valores que não vieram no formulário
+	var current map[string]interface{}
+	if c.schema.HasUploads() || len(c.schema.Rules) > 0 {
+		if current, err = c.repo.FindByID(key); err != nil {
+			log.Printf("Erro ao buscar registro para atualização: %v", err)
+			http.Error(w, "Registro não encontrado", http.StatusNotFound)
+			return
+		}
+	}
+
+	data, validationErrors := validators.ValidatePartial(r.PostForm, c.schema)
+	uploads := c.collectUploads(r, current, data, validationErrors)
+	validators.CheckRules(c.schema, mergeRecord(current, data), data, validationErrors)
+
+	// Versão carregada pelo formulário (lock otimista): obrigatória, senão a gravação
+	// sobrescreveria uma alteração concorrente sem perceber
+	if versionField := c.schema.VersionField; versionField != "" {
+		version, err := strconv.Atoi(r.PostForm.Get(versionField))
+		if err != nil {
+			validationErrors["_form"] = "Versão do registro ausente ou inválida. Recarregue a página."
+		} else {
+			data[versionField] = version
+		}
+	}
+
 	if len(validationErrors) > 0 {
 		c.reloadPageWithErrors(w, r, validationErrors, r.PostForm)
 		return
 	}
 
-	var pkValue interface{}
-	for _, field := range c.schema.Fields {
-		if field.PrimaryKey {
-			pkValue = data[field.Name]
-			break
-		}
+	stored, err := c.storeUploads(uploads, data)
+	if err != nil {
+		log.Printf("Erro ao gravar arquivos: %v", err)
+		validationErrors["_form"] = "Erro interno ao salvar os arquivos enviados."
+		c.reloadPageWithErrors(w, r, validationErrors, r.PostForm)
+		return
 	}
 
-	if err := c.repo.Update(pkValue, data); err != nil {
+	if err := c.repo.Update(key, data); err != nil {
+		c.deleteFiles(stored)
+		var conflict *models.ConflictError
+		if errors.As(err, &conflict) {
+			c.reloadPageWithConflict(w, r, conflict.Current)
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			validationErrors["_form"] = "Este registro foi removido por outro usuário."
+			c.reloadPageWithStatus(w, r, http.StatusNotFound, validationErrors, r.PostForm)
+			return
+		}
+		if c.duplicateFieldError(err, validationErrors) {
+			c.reloadPageWithErrors(w, r, validationErrors, r.PostForm)
+			return
+		}
 		log.Printf("Erro ao atualizar registro: %v", err)
 		validationErrors["_form"] = "Erro interno ao atualizar."
 		c.reloadPageWithErrors(w, r, validationErrors, r.PostForm)
 		return
 	}
 
-	http.Redirect(w, r, "/", http.StatusFound)
+	// Arquivos substituídos ou removidos não são mais referenciados
+	c.deleteFiles(c.replacedFiles(current, data))
+
+	http.Redirect(w, r, c.formListParams(r.PostForm).url(), http.StatusFound)
+}
+
+// mergeRecord devolve o registro gravado com as alterações aplicadas (usado nas regras
+// entre campos de uma atualização parcial)
+func mergeRecord(current, changes map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(current)+len(changes))
+	for name, value := range current {
+		merged[name] = value
+	}
+	for name, value := range changes {
+		merged[name] = value
+	}
+	return merged
+}
+
+// Erro de um campo unique (ou da chave) com valor que outro registro já tem
+const duplicateMessage = "Valor já cadastrado"
+
+// duplicateFieldError preenche o erro de valor já cadastrado (coluna unique ou chave)
+// e indica se err era desse tipo
+func (c *CRUDController) duplicateFieldError(err error, validationErrors map[string]string) bool {
+	var duplicate *models.DuplicateError
+	if !errors.As(err, &duplicate) {
+		return false
+	}
+	if duplicate.Field != "" {
+		validationErrors[duplicate.Field] = duplicateMessage
+	} else {
+		validationErrors["_form"] = "Registro já cadastrado."
+	}
+	return true
 }
 
 // handleDelete processa a exclusão de um item (via POST para segurança)
@@ -192,27 +355,35 @@ func (c *CRUDController) handleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		http.Error(w, "ID ausente", http.StatusBadRequest)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao parsear formulário", http.StatusBadRequest)
 		return
 	}
 
-	// Converte ID para o tipo da PK (assumindo int por simplicidade)
-	// Em um sistema real, leríamos o tipo da PK do schema
-	idInt, err := strconv.Atoi(id)
+	// A chave vem nos campos ocultos do formulário de exclusão (ou na query string)
+	key, err := c.parseKey(r.Form)
 	if err != nil {
-		http.Error(w, "ID inválido", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if err := c.repo.Delete(idInt); err != nil {
+	// Guarda os arquivos do registro para removê-los depois da exclusão
+	var files []string
+	if c.schema.HasUploads() {
+		if record, err := c.repo.FindByID(key); err == nil {
+			files = c.recordFiles(record)
+		}
+	}
+
+	if err := c.repo.Delete(key); err != nil {
 		log.Printf("Erro ao deletar registro: %v", err)
 		http.Error(w, "Erro ao deletar registro", http.StatusInternalServerError)
 		return
 	}
 
-	http.Redirect(w, r, "/", http.StatusFound)
+	c.deleteFiles(files)
+
+	http.Redirect(w, r, c.formListParams(r.Form).url(), http.StatusFound)
 }
 
 // handleGetByID é usado pelo AJAX para popular o formulário de edição
@@ -222,36 +393,39 @@ func (c *CRUDController) handleGetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		http.Error(w, "ID ausente", http.StatusBadRequest)
-		return
-	}
-
-	idInt, err := strconv.Atoi(id)
+	key, err := c.parseKey(r.URL.Query())
 	if err != nil {
-		http.Error(w, "ID inválido", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	data, err := c.repo.FindByID(idInt)
-	
-	validators.FormatSingleDataBySchema(c.schema, data)
-
+	data, err := c.repo.FindByID(key)
 	if err != nil {
 		log.Printf("Erro ao buscar por ID: %v", err)
 		http.Error(w, "Registro não encontrado", http.StatusNotFound)
 		return
 	}
 
+	validators.FormatSingleDataBySchema(c.schema, data)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
 
+// parseKey lê a chave primária (um parâmetro por coluna) e converte para os tipos do schema.
+// A lista exibe os valores já formatados pela máscara, então a máscara é removida antes.
+func (c *CRUDController) parseKey(values url.Values) (models.Key, error) {
+	cleaned := url.Values{}
+	for _, field := range c.schema.PrimaryKeyFields() {
+		cleaned.Set(field.Name, validators.CleanValueByMask(field, values.Get(field.Name)))
+	}
+	return c.schema.ParseKey(cleaned)
+}
 
 // renderTemplate renderiza o template HTML com os dados fornecidos
 func (c *CRUDController) renderTemplate(w http.ResponseWriter, data TemplateData) {
-	err := c.tmpl.ExecuteTemplate(w, "crud.html", data)
+	data.Theme = c.schema.Theme.WithDefaults(c.theme)
+	err := c.tmpl.ExecuteTemplate(w, pageTemplate, data)
 	if err != nil {
 		log.Printf("Erro ao renderizar template: %v", err)
 		http.Error(w, "Erro ao renderizar página", http.StatusInternalServerError)
@@ -260,49 +434,88 @@ func (c *CRUDController) renderTemplate(w http.ResponseWriter, data TemplateData
 
 // reloadPageWithErrors recarrega a página de lista, injetando os erros de validação
 func (c *CRUDController) reloadPageWithErrors(w http.ResponseWriter, r *http.Request, errors map[string]string, formData map[string][]string) {
-	pageStr := r.URL.Query().Get("page")
-	search := r.URL.Query().Get("search")
-	page, _ := strconv.Atoi(pageStr)
-	if page <= 0 {
-		page = 1
-	}
+	c.reloadPageWithStatus(w, r, http.StatusBadRequest, errors, formData)
+}
 
-	data, totalRecords, err := c.repo.FindAll(page, defaultPageLimit, search)
+// reloadPageWithStatus recarrega a página de lista com os erros e o status HTTP informados
+func (c *CRUDController) reloadPageWithStatus(w http.ResponseWriter, r *http.Request, status int, errors map[string]string, formData map[string][]string) {
+	// A lista volta na página, busca e ordenação de onde o formulário foi enviado
+	templateData, err := c.listData(c.formListParams(r.PostForm))
 	if err != nil {
 		log.Printf("Erro ao buscar dados: %v", err)
 		http.Error(w, "Erro ao buscar dados", http.StatusInternalServerError)
 		return
 	}
 
-	totalPages := int(math.Ceil(float64(totalRecords) / float64(defaultPageLimit)))
-	pagination := Pagination{
-		CurrentPage: page,
-		TotalPages:  totalPages,
-		HasPrev:     page > 1,
-		PrevPage:    page - 1,
-		HasNext:     page < totalPages,
-		NextPage:    page + 1,
-	}
-
-	// Converte url.Values (map[string][]string) para map[string]string
+	// Converte url.Values (map[string][]string) para map[string]string.
+	// Usa o último valor: checkboxes enviam um "0" oculto seguido do "1" quando marcados.
 	simpleFormData := make(map[string]string)
 	for k, v := range formData {
 		if len(v) > 0 {
-			simpleFormData[k] = v[0]
+			simpleFormData[k] = v[len(v)-1]
 		}
 	}
 
-	templateData := TemplateData{
-		Schema:     c.schema,
-		Data:       data,
-		SearchTerm: search,
-		Pagination: pagination,
-		Errors:     errors,
-		FormData:   simpleFormData,
-		CurrentTime: time.Now().Unix(),
-		SchemaColspan: len(c.schema.Fields) + 1,
+	templateData.Errors = errors
+	templateData.FormData = simpleFormData
+
+	// Formulário de edição devolvido com erros: mantém a chave do registro
+	if r.URL.Path == "/update" {
+		if _, err := c.parseKey(r.URL.Query()); err == nil {
+			templateData.EditKey = c.schema.KeyQuery(queryToRow(r.URL.Query()))
+			templateData.EditURL = template.URL("/update?" + templateData.EditKey)
+		}
 	}
 
-	w.WriteHeader(http.StatusBadRequest) // Indica que foi um request inválido
+	w.WriteHeader(status) // Indica que foi um request inválido
 	c.renderTemplate(w, templateData)
 }
+
+// reloadPageWithConflict recarrega o formulário de edição quando o registro foi alterado
+// por outra pessoa, indicando os valores atuais dos campos que divergem do que foi enviado.
+// Só os campos enviados são comparados (os demais não seriam gravados) e uploads ficam de
+// fora, pois o formulário não traz o arquivo atual. A versão do formulário passa a ser a
+// atual, então salvar de novo sobrescreve conscientemente.
+func (c *CRUDController) reloadPageWithConflict(w http.ResponseWriter, r *http.Request, current map[string]interface{}) {
+	validators.FormatSingleDataBySchema(c.schema, current)
+
+	errs := map[string]string{
+		"_form": "Este registro foi alterado por outro usuário desde que foi carregado. Revise os valores atuais indicados e salve novamente para sobrescrever.",
+	}
+
+	for _, field := range c.schema.Fields {
+		values := r.PostForm[field.Name]
+		if field.PrimaryKey || field.IsUpload() || len(values) == 0 {
+			continue
+		}
+		currentValue := ""
+		if v := current[field.Name]; v != nil {
+			currentValue = fmt.Sprint(v)
+		}
+		submitted := values[len(values)-1] // Último valor (checkboxes enviam "0" antes)
+		if currentValue != submitted {
+			if currentValue == "" {
+				errs[field.Name] = "Valor atual: (vazio)"
+			} else {
+				errs[field.Name] = "Valor atual: " + currentValue
+			}
+		}
+	}
+
+	if versionField := c.schema.VersionField; versionField != "" {
+		r.PostForm.Set(versionField, fmt.Sprint(current[versionField]))
+	}
+
+	c.reloadPageWithStatus(w, r, http.StatusConflict, errs, r.PostForm)
+}
+
+// queryToRow converte os parâmetros de uma query string em um registro (primeiro valor de cada)
+func queryToRow(values url.Values) map[string]interface{} {
+	row := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if len(v) > 0 {
+			row[k] = v[0]
+		}
+	}
+	return row
+}
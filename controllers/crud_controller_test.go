@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-crud-generator/models"
+	"go-crud-generator/validators"
+)
+
+func TestMergeRecord(t *testing.T) {
+	current := map[string]interface{}{"id": 1, "nome": "Ana", "obs": "antiga", "fim": nil}
+	changes := map[string]interface{}{"obs": nil, "fim": "2024-05-10"}
+
+	merged := mergeRecord(current, changes)
+	want := map[string]interface{}{"id": 1, "nome": "Ana", "obs": nil, "fim": "2024-05-10"}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("mergeRecord = %v, esperava %v", merged, want)
+	}
+	if current["obs"] != "antiga" || len(changes) != 2 {
+		t.Error("mergeRecord alterou os mapas de entrada")
+	}
+	if got := mergeRecord(nil, changes); !reflect.DeepEqual(got, changes) {
+		t.Errorf("sem registro atual = %v", got)
+	}
+}
+
+// O caminho de handleUpdate: só os campos enviados são gravados, e as regras veem o
+// registro gravado com as alterações
+func TestPartialUpdateChecksMergedRecord(t *testing.T) {
+	schema := &models.Schema{
+		TableName: "pedidos",
+		Fields: []models.Field{
+			{Name: "id", Type: "int", PrimaryKey: true},
+			{Name: "cliente", Type: "string", Required: true},
+			{Name: "inicio", Type: "date", Required: true},
+			{Name: "fim", Type: "date"},
+		},
+		Rules: []models.Rule{{Check: "fim >= inicio", Fields: []string{"fim"}, Message: "O fim deve ser depois do início"}},
+	}
+	current := map[string]interface{}{
+		"id": 1, "cliente": "Ana",
+		"inicio": time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+		"fim":    time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+	}
+
+	update := func(form url.Values) (map[string]interface{}, map[string]string) {
+		data, errs := validators.ValidatePartial(form, schema)
+		validators.CheckRules(schema, mergeRecord(current, data), data, errs)
+		return data, errs
+	}
+
+	// cliente e inicio não foram enviados: ficam fora da gravação, sem erro de obrigatório
+	data, errs := update(url.Values{"fim": {"2024-05-15"}})
+	if len(errs) > 0 {
+		t.Errorf("fim válido: erros %v", errs)
+	}
+	if _, ok := data["cliente"]; ok || len(data) != 1 {
+		t.Errorf("campos ausentes não deveriam ser gravados: %v", data)
+	}
+
+	// A regra compara o fim enviado com o início gravado
+	if _, errs := update(url.Values{"fim": {"2024-05-01"}}); errs["fim"] != "O fim deve ser depois do início" {
+		t.Errorf("fim antes do início gravado: erros %v", errs)
+	}
+}
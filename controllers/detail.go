@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"go-crud-generator/models"
+	"go-crud-generator/validators"
+)
+
+// Template de entrada da página de um registro
+const detailTemplate = "detail.html"
+
+// Máximo de registros exibidos por tabela relacionada na página do registro
+const maxChildRows = 20
+
+// Colunas exibidas como metadados do registro (e não entre os campos), quando existem
+var (
+	createdColumns = []string{"created_at", "criado_em"}
+	updatedColumns = []string{"updated_at", "atualizado_em"}
+)
+
+// MetadataItem é um metadado exibido na página do registro (ex.: "Criado em")
+type MetadataItem struct {
+	Label string
+	Value string
+}
+
+// DetailData são os dados da página de um registro (detail.html). Schema e Theme vêm de
+// TemplateData, que também é a página repassada à parte "cell" de cada valor.
+type DetailData struct {
+	TemplateData
+	Record       map[string]interface{} // Registro formatado para exibição
+	DetailFields []models.Field         // Campos exibidos (sem os metadados)
+	Metadata     []MetadataItem         // Criação, alteração e versão
+	Related      []*models.ChildRows    // Registros de outras tabelas que apontam para este
+	BackURL      string                 // Volta para a lista na mesma página, busca e ordenação
+}
+
+// handleView exibe um registro em modo leitura: os campos formatados, os metadados
+// (criação, alteração e versão) e os registros de outras tabelas que apontam para ele
+func (c *CRUDController) handleView(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	key, err := c.parseKey(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	record, err := c.repo.FindByID(key)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Registro não encontrado", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar por ID: %v", err)
+		http.Error(w, "Erro ao buscar registro", http.StatusInternalServerError)
+		return
+	}
+
+	data := DetailData{
+		TemplateData: TemplateData{Schema: c.schema, Theme: c.schema.Theme.WithDefaults(c.theme)},
+		Related:      c.relatedRecords(record),
+		Metadata:     c.recordMetadata(record),
+		DetailFields: detailFields(c.schema),
+		BackURL:      c.formListParams(query).url(),
+	}
+	validators.FormatDataBySchema(c.schema, []map[string]interface{}{record})
+	data.Record = record
+
+	if err := c.tmpl.ExecuteTemplate(w, detailTemplate, data); err != nil {
+		log.Printf("Erro ao renderizar template: %v", err)
+		http.Error(w, "Erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// relatedRecords busca os registros das tabelas filhas. Falhas (ex.: usuário sem acesso a
+// information_schema ou à tabela filha) são registradas e a relação fica de fora da página.
+func (c *CRUDController) relatedRecords(record map[string]interface{}) []*models.ChildRows {
+	related := []*models.ChildRows{}
+	relations, err := c.repo.ChildRelations()
+	if err != nil {
+		log.Printf("⚠️ Não foi possível ler as relações de %s: %v", c.schema.TableName, err)
+		return related
+	}
+	for _, relation := range relations {
+		children, err := c.repo.FindChildren(relation, record, maxChildRows)
+		if err != nil {
+			log.Printf("⚠️ Não foi possível ler os registros de %s: %v", relation.Table, err)
+			continue
+		}
+		related = append(related, children)
+	}
+	return related
+}
+
+// recordMetadata monta os metadados do registro a partir das colunas de criação e
+// alteração e da versão do lock otimista
+func (c *CRUDController) recordMetadata(record map[string]interface{}) []MetadataItem {
+	items := []MetadataItem{}
+	add := func(label string, columns []string) {
+		for _, column := range columns {
+			if value := record[column]; value != nil {
+				items = append(items, MetadataItem{Label: label, Value: c.metadataValue(column, value)})
+				return
+			}
+		}
+	}
+	add("Criado em", createdColumns)
+	add("Alterado em", updatedColumns)
+	if c.schema.VersionField != "" {
+		add("Versão", []string{c.schema.VersionField})
+	}
+	return items
+}
+
+// metadataValue formata o valor de um metadado. Colunas fora do schema chegam como texto;
+// datas nesse texto são exibidas como as do schema.
+func (c *CRUDController) metadataValue(column string, value interface{}) string {
+	if field, ok := c.schema.FieldByName(column); ok {
+		return validators.FormatDisplayValue(field, value)
+	}
+	if text, ok := value.(string); ok {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05"} {
+			if t, err := time.Parse(layout, text); err == nil {
+				return validators.FormatDisplayValue(models.Field{Type: "datetime"}, t)
+			}
+		}
+	}
+	return validators.FormatDisplayValue(models.Field{}, value)
+}
+
+// detailFields lista os campos exibidos na página do registro: os metadados ficam de fora
+func detailFields(schema *models.Schema) []models.Field {
+	fields := []models.Field{}
+	for _, field := range schema.Fields {
+		if !slices.Contains(createdColumns, field.Name) && !slices.Contains(updatedColumns, field.Name) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,437 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"go-crud-generator/models"
+)
+
+// OpenAPISpec descreve as rotas registradas por RegisterRoutes para o schema informado,
+// no formato OpenAPI 3.0. Os valores seguem o formato do formulário (o mesmo que /get
+// retorna): datas AAAA-MM-DD, bool "1"/"0" e strings com máscara aplicada.
+func OpenAPISpec(schema *models.Schema) map[string]interface{} {
+	formType := "application/x-www-form-urlencoded"
+	if schema.HasUploads() {
+		formType = "multipart/form-data"
+	}
+
+	keyParams := []interface{}{}
+	for _, field := range schema.PrimaryKeyFields() {
+		keyParams = append(keyParams, map[string]interface{}{
+			"name":        field.Name,
+			"in":          "query",
+			"required":    true,
+			"description": "Chave primária: " + field.DisplayLabel(),
+			"schema":      fieldSchema(field, false),
+		})
+	}
+
+	formBody := map[string]interface{}{
+		"required": true,
+		"content": map[string]interface{}{
+			formType: map[string]interface{}{"schema": ref("RecordForm")},
+		},
+	}
+
+	patchBody := map[string]interface{}{
+		"required": true,
+		"content": map[string]interface{}{
+			formType: map[string]interface{}{"schema": ref("RecordPatch")},
+		},
+	}
+
+	// POST e PATCH têm a mesma semântica: só os campos enviados mudam
+	updateOperation := map[string]interface{}{
+		"summary":     "Atualiza os campos enviados de um registro",
+		"description": "Campos ausentes mantêm o valor atual; um campo enviado vazio é limpo (NULL). O obrigatório só vale para os campos enviados.",
+		"parameters":  keyParams,
+		"requestBody": patchBody,
+		"responses": responses(
+			http.StatusFound, "Atualizado; redireciona para /",
+			http.StatusBadRequest, "Página HTML com os erros de validação",
+			http.StatusNotFound, "Registro não encontrado",
+			http.StatusConflict, "Registro alterado por outro usuário (lock otimista)",
+		),
+	}
+
+	paths := map[string]interface{}{
+		"/": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Lista paginada com busca e ordenação",
+				"parameters": []interface{}{
+					map[string]interface{}{"name": "page", "in": "query", "schema": map[string]interface{}{"type": "integer", "minimum": 1}},
+					map[string]interface{}{"name": "per_page", "in": "query", "schema": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": maxPageLimit, "default": defaultPageLimit}},
+					map[string]interface{}{"name": "search", "in": "query", "schema": map[string]interface{}{"type": "string"}},
+					map[string]interface{}{"name": "sort", "in": "query", "schema": map[string]interface{}{"type": "string", "enum": sortableFields(schema)}},
+					map[string]interface{}{"name": "dir", "in": "query", "schema": map[string]interface{}{"type": "string", "enum": []string{"asc", "desc"}}},
+				},
+				"responses": responses(http.StatusOK, "Página HTML da lista"),
+			},
+		},
+		"/create": map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary":     "Cria um registro",
+				"requestBody": formBody,
+				"responses": responses(
+					http.StatusFound, "Criado; redireciona para /",
+					http.StatusBadRequest, "Página HTML com os erros de validação",
+				),
+			},
+		},
+		"/update": map[string]interface{}{
+			"post":  updateOperation,
+			"patch": updateOperation,
+		},
+		"/delete": map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary":    "Exclui um registro",
+				"parameters": keyParams,
+				"responses": responses(
+					http.StatusFound, "Excluído; redireciona para /",
+					http.StatusBadRequest, "Chave inválida",
+				),
+			},
+		},
+		"/view": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":    "Página de um registro (campos, metadados e registros relacionados)",
+				"parameters": keyParams,
+				"responses": responses(
+					http.StatusOK, "Página HTML do registro",
+					http.StatusBadRequest, "Chave inválida",
+					http.StatusNotFound, "Registro não encontrado",
+				),
+			},
+		},
+		"/get": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":    "Busca um registro (valores no formato do formulário)",
+				"parameters": keyParams,
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Registro",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": ref("Record")},
+						},
+					},
+					"400": map[string]interface{}{"description": "Chave inválida"},
+					"404": map[string]interface{}{"description": "Registro não encontrado"},
+				},
+			},
+		},
+	}
+
+	paths["/api/records"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Lista JSON paginada por cursor",
+			"description": "Passe next_cursor em after (ou prev_cursor em before) para navegar. O cursor vale só para a mesma ordenação.",
+			"parameters": []interface{}{
+				map[string]interface{}{"name": "per_page", "in": "query", "schema": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": maxPageLimit, "default": defaultPageLimit}},
+				map[string]interface{}{"name": "search", "in": "query", "schema": map[string]interface{}{"type": "string"}},
+				map[string]interface{}{"name": "sort", "in": "query", "schema": map[string]interface{}{"type": "string", "enum": sortableFields(schema)}},
+				map[string]interface{}{"name": "dir", "in": "query", "schema": map[string]interface{}{"type": "string", "enum": []string{"asc", "desc"}}},
+				map[string]interface{}{"name": "after", "in": "query", "description": "Cursor: registros depois dele", "schema": map[string]interface{}{"type": "string"}},
+				map[string]interface{}{"name": "before", "in": "query", "description": "Cursor: registros antes dele", "schema": map[string]interface{}{"type": "string"}},
+				map[string]interface{}{"name": "count", "in": "query", "description": "Contagem do total (padrão: --list-count)", "schema": map[string]interface{}{"type": "string", "enum": models.CountModes}},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "Página de registros (valores no formato do formulário)",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": ref("RecordPage")},
+					},
+				},
+				"400": map[string]interface{}{"description": "Cursor, ordenação ou contagem inválidos"},
+			},
+		},
+	}
+
+	paths["/api/record"] = map[string]interface{}{
+		"patch": map[string]interface{}{
+			"summary":     "Altera campos de um registro (edição na lista)",
+			"description": "Só os campos enviados mudam, com a mesma validação do formulário; null ou \"\" limpa o campo. Chaves e arquivos não são aceitos. Com lock otimista, envie também a versão carregada.",
+			"parameters":  keyParams,
+			"requestBody": map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": map[string]interface{}{"type": "object", "additionalProperties": true}},
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "Registro atualizado: data no formato do formulário, display no da lista",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"data":    ref("Record"),
+								"display": map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+							},
+						}},
+					},
+				},
+				"400": map[string]interface{}{"description": "Chave, corpo ou campo inválidos"},
+				"404": map[string]interface{}{"description": "Registro não encontrado"},
+				"409": map[string]interface{}{"description": "Registro alterado por outro usuário (lock otimista); current traz os valores atuais"},
+				"422": map[string]interface{}{"description": "Erros de validação por campo em errors"},
+			},
+		},
+	}
+
+	paths["/api/validation"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":     "Manifesto de validação do formulário",
+			"description": "Validações de cada campo (obrigatório, validation.type, enum, regex_rules, tamanho, precisão), regras entre campos e mensagens de erro, as mesmas aplicadas pelo servidor.",
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "Manifesto",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"table":    map[string]interface{}{"type": "string"},
+								"fields":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}},
+								"rules":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}},
+								"messages": map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+							},
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	// Em /api/validate a chave é opcional: só vai na edição
+	validateParams := []interface{}{}
+	for _, param := range keyParams {
+		optional := map[string]interface{}{}
+		for name, value := range param.(map[string]interface{}) {
+			optional[name] = value
+		}
+		optional["required"] = false
+		validateParams = append(validateParams, optional)
+	}
+	paths["/api/validate"] = map[string]interface{}{
+		"post": map[string]interface{}{
+			"summary":     "Valida campos no servidor, sem gravar",
+			"description": "Os campos enviados passam pela validação do formulário (como no PATCH) e pelas conferências no banco: valor já cadastrado em campos unique ou na chave e chave estrangeira apontando para um registro inexistente. Na edição, envie a chave do registro na query string para que ele não conte como repetido.",
+			"parameters":  validateParams,
+			"requestBody": map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/x-www-form-urlencoded": map[string]interface{}{"schema": ref("RecordPatch")},
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "Resultado: valid e os erros por campo",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"valid":  map[string]interface{}{"type": "boolean"},
+								"errors": map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+							},
+						}},
+					},
+				},
+				"400": map[string]interface{}{"description": "Chave ou formulário inválidos"},
+			},
+		},
+	}
+
+	bulkFieldNames := []string{}
+	for _, field := range bulkFields(schema) {
+		bulkFieldNames = append(bulkFieldNames, field.Name)
+	}
+	paths["/bulk"] = map[string]interface{}{
+		"post": map[string]interface{}{
+			"summary":     "Ação em massa nos registros selecionados",
+			"description": "Exclusão e edição acontecem numa única transação; o resumo aparece na lista após o redirecionamento.",
+			"requestBody": map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/x-www-form-urlencoded": map[string]interface{}{
+						"schema": map[string]interface{}{
+							"type":     "object",
+							"required": []string{"keys", "action"},
+							"properties": map[string]interface{}{
+								"keys":   map[string]interface{}{"type": "array", "maxItems": maxBulkRecords, "description": "Chaves dos registros como query string (ex.: id=5)", "items": map[string]interface{}{"type": "string"}},
+								"action": map[string]interface{}{"type": "string", "enum": []string{"delete", "export", "edit"}},
+								"format": map[string]interface{}{"type": "string", "enum": []string{"csv", "json"}, "description": "Formato da exportação"},
+								"field":  map[string]interface{}{"type": "string", "enum": bulkFieldNames, "description": "Campo alterado na edição"},
+								"value":  map[string]interface{}{"type": "string", "description": "Novo valor (vazio grava NULL)"},
+							},
+						},
+					},
+				},
+			},
+			"responses": responses(
+				http.StatusOK, "Arquivo exportado (action=export)",
+				http.StatusFound, "Executado; redireciona para a lista",
+				http.StatusBadRequest, "Chave, ação, formato ou campo inválidos",
+			),
+		},
+	}
+
+	if schema.HasUploads() {
+		paths["/files/{key}"] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Baixa um arquivo enviado em um campo file/image",
+				"parameters": []interface{}{
+					map[string]interface{}{"name": "key", "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"}},
+					map[string]interface{}{"name": "download", "in": "query", "description": "Força o download em vez de exibir", "schema": map[string]interface{}{"type": "string"}},
+				},
+				"responses": responses(
+					http.StatusOK, "Conteúdo do arquivo",
+					http.StatusNotFound, "Arquivo não encontrado",
+				),
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "CRUD " + schema.TableName,
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"Record":      recordSchema(schema, false),
+				"RecordForm":  recordSchema(schema, true),
+				"RecordPatch": patchSchema(schema),
+				"RecordPage": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"data"},
+					"properties": map[string]interface{}{
+						"data":            map[string]interface{}{"type": "array", "items": ref("Record")},
+						"next_cursor":     map[string]interface{}{"type": "string", "description": "Ausente na última página"},
+						"prev_cursor":     map[string]interface{}{"type": "string", "description": "Ausente na primeira página"},
+						"total":           map[string]interface{}{"type": "integer", "description": "Ausente com count=none"},
+						"total_estimated": map[string]interface{}{"type": "boolean"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// recordSchema descreve um registro; no formulário (form) as chaves geradas não entram
+// e os uploads são o arquivo enviado
+func recordSchema(schema *models.Schema, form bool) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, field := range schema.Fields {
+		if form && schema.IsGeneratedKey(field) {
+			continue
+		}
+		properties[field.Name] = fieldSchema(field, form)
+		// Uploads podem ser omitidos na edição (o arquivo atual é mantido)
+		if (field.Required || field.PrimaryKey) && !(form && field.IsUpload()) {
+			required = append(required, field.Name)
+		}
+	}
+	if schema.VersionField != "" {
+		properties[schema.VersionField] = map[string]interface{}{
+			"type":        "integer",
+			"description": "Versão carregada (lock otimista)",
+		}
+	}
+
+	result := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+	return result
+}
+
+// patchSchema é o corpo da atualização: os campos do formulário, todos opcionais, e a
+// versão carregada, obrigatória com lock otimista
+func patchSchema(schema *models.Schema) map[string]interface{} {
+	result := recordSchema(schema, true)
+	delete(result, "required")
+	if schema.VersionField != "" {
+		result["required"] = []string{schema.VersionField}
+	}
+	return result
+}
+
+// fieldSchema traduz o tipo do campo para um schema OpenAPI
+func fieldSchema(field models.Field, form bool) map[string]interface{} {
+	s := map[string]interface{}{"type": "string"}
+	switch field.BaseType() {
+	case "int":
+		s = map[string]interface{}{"type": "integer", "format": "int32"}
+	case "bigint":
+		s = map[string]interface{}{"type": "integer", "format": "int64"}
+	case "float":
+		s = map[string]interface{}{"type": "number"}
+	case "decimal":
+		precision, scale, _ := field.DecimalSpec()
+		s["format"] = "decimal"
+		s["pattern"] = fmt.Sprintf(`^-?\d{1,%d}(\.\d{1,%d})?$`, max(precision-scale, 1), max(scale, 1))
+	case "bool":
+		s["enum"] = []string{"0", "1"}
+	case "date":
+		s["format"] = "date"
+	case "datetime":
+		s["pattern"] = `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}$`
+	case "time":
+		s["pattern"] = `^\d{2}:\d{2}(:\d{2})?$`
+	case "uuid":
+		s["format"] = "uuid"
+	case "ulid":
+		s["pattern"] = "^[0-9A-HJKMNP-TV-Z]{26}$"
+	case "json":
+		s["description"] = "Documento JSON"
+	case "file", "image":
+		if form {
+			s["format"] = "binary"
+		} else {
+			s["description"] = "Chave do arquivo (baixe em /files/{key})"
+		}
+	case "string":
+		s["maxLength"] = field.MaxLength()
+	}
+
+	if field.Validation.Type != "" {
+		s["format"] = field.Validation.Type
+	}
+	if len(field.Enum) > 0 {
+		s["enum"] = field.Enum
+	}
+	switch rules := field.Validation.RegexRules; len(rules) {
+	case 0:
+	case 1:
+		s["pattern"] = rules[0].Pattern
+	default:
+		all := []interface{}{}
+		for _, rule := range rules {
+			all = append(all, map[string]interface{}{"pattern": rule.Pattern})
+		}
+		s["allOf"] = all
+	}
+	if _, ok := s["description"]; !ok && (field.Label != "" || field.Help != "") {
+		s["description"] = field.DisplayLabel()
+		if field.Help != "" {
+			s["description"] = field.DisplayLabel() + ". " + field.Help
+		}
+	}
+	return s
+}
+
+// responses monta o objeto de respostas a partir de pares status, descrição
+func responses(pairs ...interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		result[fmt.Sprint(pairs[i])] = map[string]interface{}{"description": pairs[i+1]}
+	}
+	return result
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
@@ -0,0 +1,291 @@
+package controllers
+
+import (
+	"net/url"
+	"strconv"
+
+	"go-crud-generator/models"
+)
+
+// Registros por página: padrão, máximo aceito em ?per_page= e opções do seletor
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+var pageSizeOptions = []int{10, 25, 50, 100}
+
+// Páginas exibidas de cada lado da atual antes das reticências
+const pageLinkRadius = 2
+
+// Campo oculto dos formulários com o estado da lista (página, busca, ordenação), para
+// a página voltar igual depois de salvar ou de um erro de validação
+const listStateField = "__list"
+
+// ListConfig escolhe como a lista navega entre as páginas
+type ListConfig struct {
+	Keyset bool             // Páginas por cursor (anterior/próxima), sem OFFSET nem números de página
+	Count  models.CountMode // Contagem de registros no modo cursor (o modo numerado sempre conta)
+}
+
+// listParams é o estado da lista, lido da query string (?page=2&per_page=25&search=ana&sort=nome&dir=desc).
+// No modo cursor, after/before substituem page.
+type listParams struct {
+	Page    int
+	PerPage int
+	Search  string
+	Sort    string
+	Desc    bool
+	After   string
+	Before  string
+}
+
+// parseListParams lê o estado da lista, ignorando valores inválidos (página fora do
+// intervalo, tamanho acima do máximo, campo que não existe ou não é ordenável)
+func parseListParams(values url.Values, schema *models.Schema) listParams {
+	p := listParams{Page: 1, PerPage: defaultPageLimit, Search: values.Get("search")}
+	if page, err := strconv.Atoi(values.Get("page")); err == nil && page > 0 {
+		p.Page = page
+	}
+	if perPage, err := strconv.Atoi(values.Get("per_page")); err == nil && perPage > 0 {
+		p.PerPage = min(perPage, maxPageLimit)
+	}
+	if field, ok := schema.FieldByName(values.Get("sort")); ok && field.IsSortable() {
+		p.Sort = field.Name
+		p.Desc = values.Get("dir") == "desc"
+	}
+	if p.After = values.Get("after"); p.After == "" {
+		p.Before = values.Get("before")
+	}
+	return p
+}
+
+// options converte para a consulta do repositório
+func (p listParams) options() models.ListOptions {
+	return models.ListOptions{Page: p.Page, Limit: p.PerPage, Search: p.Search, Sort: p.Sort, Desc: p.Desc}
+}
+
+// pageOptions converte para a consulta por cursor do repositório
+func (p listParams) pageOptions(count models.CountMode) models.PageOptions {
+	return models.PageOptions{Limit: p.PerPage, Search: p.Search, Sort: p.Sort, Desc: p.Desc, After: p.After, Before: p.Before, Count: count}
+}
+
+// values codifica o estado, omitindo os valores padrão
+func (p listParams) values() url.Values {
+	values := url.Values{}
+	if p.Page > 1 {
+		values.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PerPage != defaultPageLimit {
+		values.Set("per_page", strconv.Itoa(p.PerPage))
+	}
+	if p.Search != "" {
+		values.Set("search", p.Search)
+	}
+	if p.Sort != "" {
+		values.Set("sort", p.Sort)
+		if p.Desc {
+			values.Set("dir", "desc")
+		}
+	}
+	if p.After != "" {
+		values.Set("after", p.After)
+	}
+	if p.Before != "" {
+		values.Set("before", p.Before)
+	}
+	return values
+}
+
+// url é o link da lista com esse estado
+func (p listParams) url() string {
+	if query := p.values().Encode(); query != "" {
+		return "/?" + query
+	}
+	return "/"
+}
+
+// Pagination contém dados para a paginação
+type Pagination struct {
+	CurrentPage  int
+	TotalPages   int
+	TotalRecords int
+	HasPrev      bool
+	HasNext      bool
+	PrevPage     int
+	NextPage     int
+
+	Keyset      bool // Navegação por cursor: só primeira/anterior/próxima, sem números de página
+	Counted     bool // TotalRecords é conhecido (o modo cursor pode não contar)
+	Estimated   bool // TotalRecords é uma estimativa
+	FirstURL    string
+	PageSize    int
+	PageSizes   []int  // Opções do seletor de tamanho da página
+	FirstRecord int    // Posição do primeiro registro exibido (0 se a página está vazia)
+	LastRecord  int    // Posição do último registro exibido
+	Shown       int    // Registros exibidos nesta página
+	PrevURL     string // Links mantêm busca, ordenação e tamanho da página
+	NextURL     string
+	Links       []PageLink // Páginas numeradas, com reticências nos intervalos omitidos
+	Params      []Param    // Estado da lista (sem página e tamanho) para os formulários de navegação
+}
+
+// PageLink é um item da navegação numerada
+type PageLink struct {
+	Number   int
+	URL      string
+	Current  bool
+	Ellipsis bool // Intervalo omitido ("…"), sem número nem link
+}
+
+// Param é um parâmetro da query string, repassado como campo oculto
+type Param struct {
+	Name  string
+	Value string
+}
+
+// SortLink é o link do cabeçalho de uma coluna ordenável
+type SortLink struct {
+	URL    string
+	Active bool // A lista está ordenada por essa coluna
+	Desc   bool
+}
+
+// newPagination monta a paginação da página p com total registros, dos quais shown exibidos
+func newPagination(p listParams, total, shown int) Pagination {
+	totalPages := (total + p.PerPage - 1) / p.PerPage
+	pagination := Pagination{
+		CurrentPage:  p.Page,
+		TotalPages:   totalPages,
+		TotalRecords: total,
+		Counted:      true,
+		FirstURL:     p.withPage(1).url(),
+		HasPrev:      p.Page > 1,
+		PrevPage:     p.Page - 1,
+		HasNext:      p.Page < totalPages,
+		NextPage:     p.Page + 1,
+		PageSize:     p.PerPage,
+		PageSizes:    pageSizeOptions,
+		PrevURL:      p.withPage(p.Page - 1).url(),
+		NextURL:      p.withPage(p.Page + 1).url(),
+	}
+	if shown > 0 {
+		pagination.FirstRecord = (p.Page-1)*p.PerPage + 1
+		pagination.LastRecord = pagination.FirstRecord + shown - 1
+	}
+
+	// Primeira, última e as vizinhas da atual; um intervalo de uma página só é exibido
+	// em vez das reticências
+	link := func(page int) PageLink {
+		return PageLink{Number: page, URL: p.withPage(page).url(), Current: page == p.Page}
+	}
+	prev := 0
+	for _, page := range pageNumbers(p.Page, totalPages) {
+		switch gap := page - prev; {
+		case gap == 2:
+			pagination.Links = append(pagination.Links, link(prev+1))
+		case gap > 2:
+			pagination.Links = append(pagination.Links, PageLink{Ellipsis: true})
+		}
+		pagination.Links = append(pagination.Links, link(page))
+		prev = page
+	}
+
+	pagination.Params = p.params()
+	return pagination
+}
+
+// newKeysetPagination monta a paginação por cursor a partir da página devolvida pelo repositório
+func newKeysetPagination(p listParams, page *models.Page) Pagination {
+	pagination := Pagination{
+		Keyset:       true,
+		TotalRecords: max(page.Total, 0),
+		Counted:      page.Total >= 0,
+		Estimated:    page.Estimated,
+		HasPrev:      page.Prev != "",
+		HasNext:      page.Next != "",
+		FirstURL:     p.withPage(1).url(),
+		PageSize:     p.PerPage,
+		PageSizes:    pageSizeOptions,
+		Shown:        len(page.Rows),
+		Params:       p.params(),
+	}
+	switch {
+	case page.Prev != "":
+		prev := p.withPage(1)
+		prev.Before = page.Prev
+		pagination.PrevURL = prev.url()
+	case p.After != "" || p.Before != "":
+		// Página vazia depois do cursor (ex.: os registros seguintes foram excluídos)
+		pagination.HasPrev = true
+		pagination.PrevURL = pagination.FirstURL
+	}
+	if pagination.HasNext {
+		next := p.withPage(1)
+		next.After = page.Next
+		pagination.NextURL = next.url()
+	}
+	return pagination
+}
+
+// params lista a busca e a ordenação para os formulários de navegação, que trocam só a
+// página ou o tamanho dela
+func (p listParams) params() []Param {
+	params := []Param{}
+	state := p.values()
+	for _, name := range []string{"search", "sort", "dir"} {
+		if value := state.Get(name); value != "" {
+			params = append(params, Param{Name: name, Value: value})
+		}
+	}
+	return params
+}
+
+// sortLinks monta os links dos cabeçalhos: clicar na coluna ordena por ela (crescente) e
+// clicar de novo inverte a ordem. A ordenação volta para a primeira página.
+func sortLinks(p listParams, schema *models.Schema) map[string]SortLink {
+	links := map[string]SortLink{}
+	for _, field := range schema.Fields {
+		if !field.IsSortable() {
+			continue
+		}
+		next := p.withPage(1)
+		next.Sort = field.Name
+		next.Desc = p.Sort == field.Name && !p.Desc
+		links[field.Name] = SortLink{URL: next.url(), Active: p.Sort == field.Name, Desc: p.Desc}
+	}
+	return links
+}
+
+// withPage troca a página, descartando o cursor
+func (p listParams) withPage(page int) listParams {
+	p.Page = page
+	p.After, p.Before = "", ""
+	return p
+}
+
+// pageNumbers lista, em ordem, a primeira página, as vizinhas da atual e a última
+func pageNumbers(current, total int) []int {
+	if total == 0 {
+		return nil
+	}
+	pages := []int{1}
+	for page := max(2, current-pageLinkRadius); page <= min(total-1, current+pageLinkRadius); page++ {
+		pages = append(pages, page)
+	}
+	if total > 1 {
+		pages = append(pages, total)
+	}
+	return pages
+}
+
+// sortableFields lista os campos aceitos em ?sort=
+func sortableFields(schema *models.Schema) []string {
+	names := []string{}
+	for _, field := range schema.Fields {
+		if field.IsSortable() {
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-crud-generator/models"
+)
+
+// Template de entrada da página (os demais são as partes que ele monta)
+const pageTemplate = "crud.html"
+
+// Partials são as partes da página que podem ser substituídas. Além do arquivo inteiro,
+// cada parte aceita versões por entidade e por campo, definidas com {{define}} em qualquer
+// .html do diretório de templates e escolhidas da mais específica para a mais geral:
+// "field:clientes.cpf", "field:clientes" e "field".
+var Partials = []string{"layout", "form", "field", "table", "cell", "pagination", "detail"}
+
+// FieldData é o contexto das partes "field" (formulário) e "cell" (tabela)
+type FieldData struct {
+	Page  TemplateData
+	Field models.Field
+	Value interface{}            // Valor do formulário ("field") ou do registro ("cell")
+	Error string                 // Erro de validação do campo (só em "field")
+	Row   map[string]interface{} // Registro da linha (só em "cell")
+}
+
+// ParseTemplates lê todos os .html de fsys (a página e as partes) e registra as funções
+// usadas por eles
+func ParseTemplates(fsys fs.FS) (*template.Template, error) {
+	var tmpl *template.Template
+	funcs := template.FuncMap{
+		// partial executa a versão mais específica da parte: partial "field" dados "clientes" "cpf"
+		"partial": func(name string, data interface{}, qualifiers ...string) (template.HTML, error) {
+			for i := len(qualifiers); i >= 0; i-- {
+				candidate := name
+				if i > 0 {
+					candidate += ":" + strings.Join(qualifiers[:i], ".")
+				}
+				if tmpl.Lookup(candidate) == nil {
+					continue
+				}
+				var buf bytes.Buffer
+				if err := tmpl.ExecuteTemplate(&buf, candidate, data); err != nil {
+					return "", err
+				}
+				return template.HTML(buf.String()), nil
+			}
+			return "", fmt.Errorf("template %q não definido", name)
+		},
+		"field": func(page TemplateData, field models.Field) FieldData {
+			return FieldData{Page: page, Field: field, Value: page.FormData[field.Name], Error: page.Errors[field.Name]}
+		},
+		"cell": func(page TemplateData, row map[string]interface{}, field models.Field) FieldData {
+			return FieldData{Page: page, Field: field, Value: row[field.Name], Row: row}
+		},
+		// editable indica se a célula do campo pode ser editada na lista
+		"editable": isBulkEditable,
+		// viewURL é o link da página do registro, que volta para a lista no mesmo estado
+		"viewURL": func(page TemplateData, row map[string]interface{}) template.URL {
+			back := url.Values{listStateField: {page.ListState}}
+			return template.URL("/view?" + page.Schema.KeyQuery(row) + "&" + back.Encode())
+		},
+	}
+
+	tmpl, err := template.New(pageTemplate).Funcs(funcs).ParseFS(fsys, "*.html")
+	if err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// CheckTemplate executa o template com um schema sem registros para detectar
+// erros que só aparecem na renderização (ex.: campo inexistente) e confere que as
+// versões por campo das partes citam campos do schema
+func CheckTemplate(tmpl *template.Template, schema *models.Schema) error {
+	if err := checkOverrides(tmpl, schema); err != nil {
+		return err
+	}
+	data := TemplateData{
+		Schema:        schema,
+		Theme:         schema.Theme,
+		BulkFields:    bulkFields(schema),
+		SchemaColspan: len(schema.Fields) + 2,
+	}
+	if err := tmpl.ExecuteTemplate(io.Discard, pageTemplate, data); err != nil {
+		return err
+	}
+
+	// Página do registro, com um registro vazio
+	detail := DetailData{TemplateData: data, Record: map[string]interface{}{}, DetailFields: detailFields(schema)}
+	return tmpl.ExecuteTemplate(io.Discard, detailTemplate, detail)
+}
+
+// checkOverrides rejeita partes desconhecidas (ex.: "feld:clientes.cpf") e versões
+// para campos que não existem na entidade do schema
+func checkOverrides(tmpl *template.Template, schema *models.Schema) error {
+	names := []string{}
+	for _, t := range tmpl.Templates() {
+		names = append(names, t.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		partial, qualifier, ok := strings.Cut(name, ":")
+		if !ok {
+			continue
+		}
+		if !slices.Contains(Partials, partial) {
+			return fmt.Errorf("template %q: parte desconhecida %q (partes: %s)", name, partial, strings.Join(Partials, ", "))
+		}
+		entity, fieldName, hasField := strings.Cut(qualifier, ".")
+		if entity != schema.TableName || !hasField {
+			continue // Versões de outras entidades são ignoradas
+		}
+		if partial != "field" && partial != "cell" {
+			return fmt.Errorf("template %q: só as partes field e cell têm versões por campo", name)
+		}
+		if _, ok := schema.FieldByName(fieldName); !ok {
+			return fmt.Errorf("template %q: o campo %q não existe no schema", name, fieldName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go-crud-generator/models"
+	"go-crud-generator/storage"
+)
+
+// Memória usada por ParseMultipartForm antes de gravar partes em arquivos temporários
+const multipartMemory = 8 << 20
+
+// Sufixo do checkbox "Remover" de campos de arquivo opcionais no formulário de edição
+const removeFileSuffix = "__remove"
+
+var extRegex = regexp.MustCompile(`^\.[a-z0-9]{1,8}$`)
+
+// Extensões usadas para imagens cujo nome original não bate com o conteúdo
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// pendingUpload é um arquivo recebido e já validado, aguardando gravação no storage
+type pendingUpload struct {
+	field       models.Field
+	header      *multipart.FileHeader
+	contentType string
+}
+
+// parseRequestForm parseia o formulário; schemas com campos file/image aceitam multipart/form-data
+func (c *CRUDController) parseRequestForm(w http.ResponseWriter, r *http.Request) error {
+	if !c.schema.HasUploads() {
+		return r.ParseForm()
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, c.schema.MaxRequestBytes())
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.ParseMultipartForm(multipartMemory)
+	}
+	return r.ParseForm()
+}
+
+// collectUploads valida os arquivos enviados (obrigatoriedade, tamanho e tipo MIME real do conteúdo).
+// current é o registro atual na edição (nil no cadastro): campos sem novo arquivo mantêm o atual.
+// Campos marcados para remoção recebem nil em data.
+func (c *CRUDController) collectUploads(r *http.Request, current map[string]interface{}, data map[string]interface{}, errs map[string]string) []pendingUpload {
+	uploads := []pendingUpload{}
+
+	for _, field := range c.schema.Fields {
+		if !field.IsUpload() {
+			continue
+		}
+
+		var header *multipart.FileHeader
+		if r.MultipartForm != nil && len(r.MultipartForm.File[field.Name]) > 0 {
+			header = r.MultipartForm.File[field.Name][0]
+		}
+
+		if header == nil || header.Size == 0 {
+			hasCurrent := current != nil && current[field.Name] != nil
+			remove := r.PostForm.Get(field.Name+removeFileSuffix) != ""
+			switch {
+			// Na edição, o campo sem novo arquivo não muda: o obrigatório só vale ao remover o atual
+			case field.Required && (current == nil || remove):
+				errs[field.Name] = "Campo obrigatório"
+			case remove && hasCurrent:
+				data[field.Name] = nil
+			}
+			continue
+		}
+
+		if header.Size > field.MaxUploadBytes() {
+			errs[field.Name] = fmt.Sprintf("Arquivo maior que %d MB", field.MaxUploadBytes()>>20)
+			continue
+		}
+
+		contentType, err := detectContentType(header)
+		if err != nil {
+			log.Printf("Erro ao ler upload do campo %s: %v", field.Name, err)
+			errs[field.Name] = "Não foi possível ler o arquivo"
+			continue
+		}
+		if !field.AcceptsContentType(contentType) {
+			errs[field.Name] = "Tipo de arquivo não permitido"
+			continue
+		}
+
+		uploads = append(uploads, pendingUpload{field: field, header: header, contentType: contentType})
+	}
+
+	return uploads
+}
+
+// storeUploads grava os arquivos no storage e coloca as chaves geradas em data.
+// Em caso de erro, remove o que já tinha sido gravado.
+func (c *CRUDController) storeUploads(uploads []pendingUpload, data map[string]interface{}) ([]string, error) {
+	stored := []string{}
+
+	for _, upload := range uploads {
+		key := fmt.Sprintf("%s/%s/%s%s", c.schema.TableName, upload.field.Name, models.NewULID(), fileExtension(upload.header.Filename, upload.contentType))
+
+		if err := c.saveUpload(key, upload); err != nil {
+			c.deleteFiles(stored)
+			return nil, fmt.Errorf("falha ao gravar arquivo do campo %s: %w", upload.field.Name, err)
+		}
+
+		stored = append(stored, key)
+		data[upload.field.Name] = key
+	}
+
+	return stored, nil
+}
+
+func (c *CRUDController) saveUpload(key string, upload pendingUpload) error {
+	file, err := upload.header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return c.storage.Save(key, file, upload.contentType)
+}
+
+// replacedFiles lista as chaves antigas de campos de arquivo que foram trocados ou removidos em data
+func (c *CRUDController) replacedFiles(current, data map[string]interface{}) []string {
+	keys := []string{}
+	for _, field := range c.schema.Fields {
+		if !field.IsUpload() {
+			continue
+		}
+		if _, changed := data[field.Name]; !changed {
+			continue
+		}
+		if old, ok := current[field.Name].(string); ok && old != "" {
+			keys = append(keys, old)
+		}
+	}
+	return keys
+}
+
+// recordFiles lista as chaves de todos os arquivos de um registro
+func (c *CRUDController) recordFiles(record map[string]interface{}) []string {
+	keys := []string{}
+	for _, field := range c.schema.Fields {
+		if key, ok := record[field.Name].(string); ok && field.IsUpload() && key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// deleteFiles remove arquivos do storage; falhas são apenas registradas (o registro já foi salvo)
+func (c *CRUDController) deleteFiles(keys []string) {
+	for _, key := range keys {
+		if err := c.storage.Delete(key); err != nil {
+			log.Printf("Erro ao remover arquivo %s: %v", key, err)
+		}
+	}
+}
+
+// handleFile serve um arquivo enviado: /files/<chave>. Imagens e PDFs abrem no navegador;
+// os demais (ou com ?download=1) são baixados, para que HTML enviado como arquivo nunca seja renderizado.
+func (c *CRUDController) handleFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/files/")
+	if !storage.ValidKey(key) {
+		http.Error(w, "Arquivo inválido", http.StatusBadRequest)
+		return
+	}
+
+	file, err := c.storage.Open(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "Arquivo não encontrado", http.StatusNotFound)
+			return
+		}
+		log.Printf("Erro ao abrir arquivo %s: %v", key, err)
+		http.Error(w, "Erro ao abrir arquivo", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	contentType := storage.ContentTypeByKey(key)
+	inline := (strings.HasPrefix(contentType, "image/") && contentType != "image/svg+xml") || contentType == "application/pdf"
+	disposition := "attachment"
+	if inline && r.URL.Query().Get("download") == "" {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": filepath.Base(key)}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("Erro ao enviar arquivo %s: %v", key, err)
+	}
+}
+
+// detectContentType identifica o tipo MIME pelo conteúdo (o Content-Type enviado pelo navegador não é confiável)
+func detectContentType(header *multipart.FileHeader) (string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// fileExtension escolhe a extensão da chave a partir do nome original, desde que seja simples
+// e coerente com o conteúdo; imagens sempre recebem a extensão do tipo detectado, para serem exibidas
+func fileExtension(filename, contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	if byExt, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext)); byExt == mediaType && extRegex.MatchString(ext) {
+		return ext
+	}
+	if imageExt, ok := imageExtensions[mediaType]; ok {
+		return imageExt
+	}
+	if extRegex.MatchString(ext) {
+		return ext
+	}
+	return ""
+}
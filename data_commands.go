@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-crud-generator/config"
+	"go-crud-generator/fake"
+	"go-crud-generator/models"
+	"go-crud-generator/validators"
+
+	"gopkg.in/yaml.v3"
+)
+
+// dataRecord é um registro lido de um arquivo de dados, com a sua posição no arquivo
+type dataRecord struct {
+	label  string // ex.: "linha 3" (CSV) ou "registro 2" (JSON/YAML)
+	values map[string]interface{}
+	typed  bool // JSON/YAML: os valores têm tipo (no CSV tudo é texto)
+}
+
+// Tamanho padrão dos lotes de "crud-app seed --count"
+const defaultSeedBatchSize = 100
+
+// Sorteios por registro de "crud-app seed --count" até satisfazer as regras entre campos
+const maxSeedAttempts = 50
+
+// Máximo de valores por INSERT (o protocolo do MySQL aceita até 65535 placeholders)
+const maxInsertPlaceholders = 60000
+
+// runSeed implementa "crud-app seed": insere os registros de um arquivo de fixtures
+// (--file) ou gera registros fictícios válidos (--count), validados como se viessem do formulário
+func runSeed(args []string) int {
+	fs := newCommandFlags("seed", "--file fixtures.json | --count N [--seed S] [opções]",
+		"Insere na tabela os registros de um arquivo de fixtures (.json, .yaml/.yml ou .csv),\n"+
+			"ou gera N registros fictícios que respeitam o schema (tipos, obrigatórios, enum,\n"+
+			"máscaras, regex e validações como CPF, CNPJ, CEP, telefone e email).")
+	file := fs.String("file", "", "Arquivo de fixtures: lista de objetos (JSON/YAML) ou CSV com cabeçalho")
+	count := fs.Int("count", 0, "Quantidade de registros fictícios a gerar")
+	seed := fs.Int64("seed", 0, "Semente do gerador: a mesma semente gera os mesmos registros (padrão: aleatória)")
+	batchSize := fs.Int("batch-size", defaultSeedBatchSize, "Registros por INSERT com --count")
+	cfg, code := loadCommandConfig(fs, args, config.WithDB|config.WithSchema)
+	if cfg == nil {
+		return code
+	}
+	if (*file == "") == (*count <= 0) || *batchSize <= 0 {
+		fs.Usage()
+		return 2
+	}
+	if *file != "" {
+		return loadRecords(cfg, *file, false)
+	}
+
+	seedSet := false
+	fs.Visit(func(f *flag.Flag) { seedSet = seedSet || f.Name == "seed" })
+	if !seedSet {
+		*seed = time.Now().UnixNano()
+	}
+	return generateRecords(cfg, *count, *seed, *batchSize)
+}
+
+// generateRecords gera count registros com o pacote fake e os insere em lotes. Cada
+// registro passa por validators.ValidateData antes de entrar no lote.
+func generateRecords(cfg *config.Config, count int, seed int64, batchSize int) int {
+	schema, err := models.LoadSchema(cfg.JSONSchemaPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao carregar schema: %v\n", err)
+		return 1
+	}
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao conectar ao banco de dados: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	repo := models.NewDynamicRepository(db, schema)
+
+	batchSize = min(batchSize, max(1, maxInsertPlaceholders/len(schema.Fields)))
+	fmt.Printf("🎲 Semente %d (use --seed %d para gerar os mesmos registros)\n", seed, seed)
+
+	gen := fake.NewGenerator(schema, seed)
+	batch := make([]map[string]interface{}, 0, batchSize)
+	inserted := 0
+	for i := 1; i <= count; i++ {
+		label := "registro " + strconv.Itoa(i)
+		var data map[string]interface{}
+		var errs map[string]string
+		// Regras entre campos (rules) podem recusar a combinação sorteada: tenta de novo
+		for attempt := 0; attempt == 0 || len(errs) > 0 && attempt < maxSeedAttempts; attempt++ {
+			form, err := gen.Record()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %s: %v\n", label, err)
+				return 1
+			}
+			data, errs = validators.ValidateData(form, schema)
+		}
+		if len(errs) > 0 {
+			// O gerador não conseguiu satisfazer alguma regra do schema
+			printRecordErrors(schema, label, errs)
+			return 1
+		}
+		batch = append(batch, data)
+
+		if len(batch) == batchSize || i == count {
+			if err := repo.CreateMany(batch); err != nil {
+				var dup *models.DuplicateError
+				if errors.As(err, &dup) && dup.Field != "" {
+					err = fmt.Errorf("%s: valor já cadastrado", dup.Field)
+				}
+				fmt.Fprintf(os.Stderr, "❌ Erro ao inserir os registros %d a %d: %v\n", inserted+1, i, err)
+				fmt.Fprintf(os.Stderr, "   %d registro(s) inserido(s) antes do erro\n", inserted)
+				return 1
+			}
+			inserted += len(batch)
+			batch = batch[:0]
+			fmt.Printf("   %d/%d\n", inserted, count)
+		}
+	}
+
+	fmt.Printf("✅ %d registro(s) gerado(s) na tabela '%s'\n", inserted, schema.TableName)
+	return 0
+}
+
+// loadRecords lê o arquivo e insere cada registro, reportando os erros por registro.
+// Retorna 1 se algum registro foi rejeitado.
+func loadRecords(cfg *config.Config, path string, dryRun bool) int {
+	schema, err := models.LoadSchema(cfg.JSONSchemaPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao carregar schema: %v\n", err)
+		return 1
+	}
+
+	records, err := readRecords(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %s: %v\n", path, err)
+		return 1
+	}
+	if err := checkRecordColumns(schema, records); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %s: %v\n", path, err)
+		return 1
+	}
+
+	var repo *models.DynamicRepository
+	if !dryRun {
+		db, err := config.InitDB(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Erro ao conectar ao banco de dados: %v\n", err)
+			return 1
+		}
+		defer db.Close()
+		repo = models.NewDynamicRepository(db, schema)
+	}
+
+	inserted, failed := 0, 0
+	for _, record := range records {
+		data, errs := validators.ValidateData(recordForm(schema, record), schema)
+		for _, field := range schema.Fields {
+			// Uploads entram como a chave do arquivo já presente no storage
+			if value := recordValue(record.values[field.Name]); field.IsUpload() && value != "" {
+				data[field.Name] = value
+			}
+		}
+
+		if len(errs) == 0 && repo != nil {
+			if _, err := repo.Create(data); err != nil {
+				var dup *models.DuplicateError
+				if errors.As(err, &dup) && dup.Field != "" {
+					errs[dup.Field] = "Valor já cadastrado"
+				} else {
+					errs["_form"] = err.Error()
+				}
+			}
+		}
+
+		if len(errs) > 0 {
+			failed++
+			printRecordErrors(schema, record.label, errs)
+			continue
+		}
+		inserted++
+	}
+
+	switch {
+	case dryRun && failed == 0:
+		fmt.Printf("✅ %d registro(s) válido(s) em %s\n", inserted, path)
+	case dryRun:
+		fmt.Printf("❌ %d registro(s) válido(s) e %d com erro em %s\n", inserted, failed, path)
+	case failed == 0:
+		fmt.Printf("✅ %d registro(s) inserido(s) na tabela '%s'\n", inserted, schema.TableName)
+	default:
+		fmt.Printf("❌ %d registro(s) inserido(s) e %d rejeitado(s) na tabela '%s'\n", inserted, failed, schema.TableName)
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// printRecordErrors exibe os erros de um registro na ordem dos campos do schema
+func printRecordErrors(schema *models.Schema, label string, errs map[string]string) {
+	if msg, ok := errs["_form"]; ok {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", label, msg)
+	}
+	for _, field := range schema.Fields {
+		if msg, ok := errs[field.Name]; ok {
+			fmt.Fprintf(os.Stderr, "%s: %s: %s\n", label, field.Name, msg)
+		}
+	}
+}
+
+// checkRecordColumns rejeita colunas que não existem no schema (em geral erro de digitação)
+func checkRecordColumns(schema *models.Schema, records []dataRecord) error {
+	known := map[string]bool{}
+	for _, field := range schema.Fields {
+		known[field.Name] = true
+	}
+	for _, record := range records {
+		for name := range record.values {
+			if !known[name] {
+				return fmt.Errorf("%s: coluna %q não existe no schema", record.label, name)
+			}
+		}
+	}
+	return nil
+}
+
+// recordForm monta os valores de um registro como os de um formulário enviado
+func recordForm(schema *models.Schema, record dataRecord) url.Values {
+	form := url.Values{}
+	for _, field := range schema.Fields {
+		value, ok := record.values[field.Name]
+		if !ok {
+			continue
+		}
+		// Campos json vindos de JSON/YAML são o próprio valor, não o texto do documento
+		if record.typed && field.BaseType() == "json" && value != nil {
+			raw, _ := json.Marshal(value)
+			form.Set(field.Name, string(raw))
+			continue
+		}
+		form.Set(field.Name, recordValue(value))
+	}
+	return form
+}
+
+// recordValue converte um valor lido do arquivo para o texto que o formulário enviaria
+func recordValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 {
+			return v.Format("2006-01-02")
+		}
+		return v.Format("2006-01-02T15:04:05")
+	}
+	return fmt.Sprint(value)
+}
+
+// readRecords lê os registros de um arquivo .json, .yaml/.yml ou .csv
+func readRecords(path string) ([]dataRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readCSVRecords(data)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber() // Mantém números grandes e decimais como no arquivo
+		if err := dec.Decode(&list); err != nil {
+			return nil, fmt.Errorf("JSON inválido (esperada uma lista de objetos): %w", err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("YAML inválido (esperada uma lista de objetos): %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("formato não suportado (use .json, .yaml, .yml ou .csv)")
+	}
+
+	records := make([]dataRecord, len(list))
+	for i, values := range list {
+		records[i] = dataRecord{label: "registro " + strconv.Itoa(i+1), values: values, typed: true}
+	}
+	return records, nil
+}
+
+// readCSVRecords lê um CSV cuja primeira linha traz os nomes das colunas; células
+// vazias viram valores vazios (NULL nos campos opcionais)
+func readCSVRecords(data []byte) ([]dataRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("CSV inválido: %w", err)
+	}
+
+	records := []dataRecord{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV inválido: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			values[strings.TrimSpace(name)] = row[i]
+		}
+		records = append(records, dataRecord{label: "linha " + strconv.Itoa(line), values: values})
+	}
+	return records, nil
+}
@@ -0,0 +1,95 @@
+// Package export grava registros em CSV ou JSON, no mesmo formato aceito por
+// "crud-app import" (usado por "crud-app export" e pela exportação da lista).
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"go-crud-generator/models"
+	"go-crud-generator/validators"
+)
+
+// Each percorre os registros chamando fn para cada um (ex.: DynamicRepository.ForEach)
+type Each func(fn func(row map[string]interface{}) error) error
+
+// CSV escreve um cabeçalho com os nomes dos campos e uma linha por registro,
+// com os valores no formato do formulário (ver validators.FormatFormValue)
+func CSV(w io.Writer, schema *models.Schema, each Each) (int, error) {
+	writer := csv.NewWriter(w)
+	header := make([]string, len(schema.Fields))
+	for i, field := range schema.Fields {
+		header[i] = field.Name
+	}
+	if err := writer.Write(header); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err := each(func(row map[string]interface{}) error {
+		record := make([]string, len(schema.Fields))
+		for i, field := range schema.Fields {
+			if value := row[field.Name]; value != nil {
+				record[i] = validators.FormatFormValue(field, value)
+			}
+		}
+		count++
+		return writer.Write(record)
+	})
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	return count, err
+}
+
+// JSON escreve uma lista de objetos, um registro por linha e com as chaves na
+// ordem do schema; números, booleanos e campos json mantêm o tipo
+func JSON(w io.Writer, schema *models.Schema, each Each) (int, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err := each(func(row map[string]interface{}) error {
+		var buf bytes.Buffer
+		if count > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString("\n  {")
+		for i, field := range schema.Fields {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			name, _ := json.Marshal(field.Name)
+			value, err := json.Marshal(jsonValue(field, row[field.Name]))
+			if err != nil {
+				return err
+			}
+			buf.Write(name)
+			buf.WriteString(": ")
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+		count++
+		_, err := w.Write(buf.Bytes())
+		return err
+	})
+	if err != nil {
+		return count, err
+	}
+	_, err = io.WriteString(w, "\n]\n")
+	return count, err
+}
+
+// jsonValue mantém os tipos que o JSON representa sem perda e usa o texto do
+// formulário para os demais (datas, decimais, strings com máscara)
+func jsonValue(field models.Field, value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, int64, float64, bool, json.RawMessage:
+		return v
+	}
+	return validators.FormatFormValue(field, value)
+}
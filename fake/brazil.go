@@ -0,0 +1,161 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+
+	"go-crud-generator/validators"
+)
+
+var firstNames = []string{
+	"Ana", "Maria", "Juliana", "Fernanda", "Beatriz", "Camila", "Larissa", "Patrícia", "Aline", "Gabriela",
+	"Letícia", "Mariana", "Bruna", "Carolina", "Luana", "João", "José", "Pedro", "Lucas", "Gabriel",
+	"Rafael", "Matheus", "Gustavo", "Felipe", "Bruno", "Thiago", "Rodrigo", "Carlos", "Eduardo", "Marcelo",
+}
+
+var lastNames = []string{
+	"Silva", "Santos", "Oliveira", "Souza", "Rodrigues", "Ferreira", "Alves", "Pereira", "Lima", "Gomes",
+	"Costa", "Ribeiro", "Martins", "Carvalho", "Almeida", "Lopes", "Soares", "Fernandes", "Vieira", "Barbosa",
+	"Rocha", "Dias", "Nascimento", "Andrade", "Moreira", "Nunes", "Marques", "Machado", "Mendes", "Freitas",
+}
+
+var streetTypes = []string{"Rua", "Rua", "Rua", "Avenida", "Travessa", "Alameda"}
+
+var streetNames = []string{
+	"das Flores", "XV de Novembro", "Sete de Setembro", "Tiradentes", "Santos Dumont", "Dom Pedro II",
+	"Getúlio Vargas", "Rui Barbosa", "São João", "das Palmeiras", "Marechal Deodoro", "Brasil",
+	"Independência", "José Bonifácio", "Barão do Rio Branco", "das Acácias", "Amazonas", "Paraná",
+}
+
+var neighborhoods = []string{
+	"Centro", "Jardim América", "Vila Nova", "Boa Vista", "Santa Cruz", "São José", "Bela Vista",
+	"Jardim Paulista", "Liberdade", "Vila Mariana", "Industrial", "Planalto", "Santo Antônio", "Cidade Nova",
+}
+
+// city é um município com a UF e o primeiro dígito dos CEPs da região
+type city struct {
+	name      string
+	uf        string
+	cepPrefix string
+}
+
+var cities = []city{
+	{"São Paulo", "SP", "0"}, {"Campinas", "SP", "1"}, {"Rio de Janeiro", "RJ", "2"}, {"Niterói", "RJ", "2"},
+	{"Vitória", "ES", "2"}, {"Belo Horizonte", "MG", "3"}, {"Uberlândia", "MG", "3"}, {"Salvador", "BA", "4"},
+	{"Aracaju", "SE", "4"}, {"Recife", "PE", "5"}, {"Maceió", "AL", "5"}, {"João Pessoa", "PB", "5"},
+	{"Natal", "RN", "5"}, {"Fortaleza", "CE", "6"}, {"Teresina", "PI", "6"}, {"São Luís", "MA", "6"},
+	{"Belém", "PA", "6"}, {"Manaus", "AM", "6"}, {"Brasília", "DF", "7"}, {"Goiânia", "GO", "7"},
+	{"Cuiabá", "MT", "7"}, {"Campo Grande", "MS", "7"}, {"Curitiba", "PR", "8"}, {"Londrina", "PR", "8"},
+	{"Florianópolis", "SC", "8"}, {"Joinville", "SC", "8"}, {"Porto Alegre", "RS", "9"}, {"Caxias do Sul", "RS", "9"},
+}
+
+// DDDs usados nos telefones, por UF
+var areaCodes = map[string][]string{
+	"SP": {"11", "19"}, "RJ": {"21", "24"}, "ES": {"27"}, "MG": {"31", "34"}, "BA": {"71"}, "SE": {"79"},
+	"PE": {"81"}, "AL": {"82"}, "PB": {"83"}, "RN": {"84"}, "CE": {"85"}, "PI": {"86"}, "MA": {"98"},
+	"PA": {"91"}, "AM": {"92"}, "DF": {"61"}, "GO": {"62"}, "MT": {"65"}, "MS": {"67"}, "PR": {"41", "43"},
+	"SC": {"48", "47"}, "RS": {"51", "54"},
+}
+
+var emailDomains = []string{"exemplo.com.br", "exemplo.com", "teste.com.br", "mail.exemplo.org"}
+
+var companySuffixes = []string{"Ltda", "S.A.", "ME", "EIRELI", "Comércio Ltda", "Serviços Ltda"}
+
+var loremWords = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod
+tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation
+ullamco laboris nisi aliquip ex ea commodo consequat duis aute irure in reprehenderit voluptate velit`)
+
+// person são os dados de uma pessoa fictícia, compartilhados pelos campos de um registro
+// (nome, email, endereço e telefone combinam entre si)
+type person struct {
+	first string
+	last  string
+	city  city
+}
+
+func (g *Generator) newPerson() person {
+	return person{
+		first: g.pick(firstNames),
+		last:  g.pick(lastNames) + " " + g.pick(lastNames),
+		city:  cities[g.rnd.Intn(len(cities))],
+	}
+}
+
+// CPF gera um CPF válido (só dígitos)
+func (g *Generator) CPF() string {
+	base := g.digits(9)
+	for allSame(base) {
+		base = g.digits(9)
+	}
+	return base + validators.CPFCheckDigits(base)
+}
+
+// CNPJ gera um CNPJ válido (só dígitos), de matriz (0001)
+func (g *Generator) CNPJ() string {
+	base := g.digits(8)
+	for allSame(base) {
+		base = g.digits(8)
+	}
+	base += "0001"
+	return base + validators.CNPJCheckDigits(base)
+}
+
+// cep gera um CEP (só dígitos) da faixa da cidade
+func (g *Generator) cep(c city) string {
+	return c.cepPrefix + g.digits(7)
+}
+
+// phone gera um celular (11 dígitos, com DDD da UF)
+func (g *Generator) phone(c city) string {
+	return g.pick(areaCodes[c.uf]) + "9" + g.digits(8)
+}
+
+// email gera um email a partir do nome da pessoa
+func (g *Generator) email(p person) string {
+	last := strings.Fields(p.last)[0]
+	user := fmt.Sprintf("%s.%s%d", asciiLower(p.first), asciiLower(last), g.rnd.Intn(1000))
+	return user + "@" + g.pick(emailDomains)
+}
+
+// street gera um logradouro (ex.: "Rua das Flores")
+func (g *Generator) street() string {
+	return g.pick(streetTypes) + " " + g.pick(streetNames)
+}
+
+// company gera uma razão social
+func (g *Generator) company(p person) string {
+	return strings.Fields(p.last)[0] + " & " + g.pick(lastNames) + " " + g.pick(companySuffixes)
+}
+
+// Words gera um texto com n palavras
+func (g *Generator) Words(n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = g.pick(loremWords)
+	}
+	words[0] = strings.ToUpper(words[0][:1]) + words[0][1:]
+	return strings.Join(words, " ")
+}
+
+func (g *Generator) digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + g.rnd.Intn(10))
+	}
+	return string(b)
+}
+
+func (g *Generator) pick(list []string) string {
+	return list[g.rnd.Intn(len(list))]
+}
+
+func allSame(s string) bool {
+	return strings.Count(s, s[:1]) == len(s)
+}
+
+// asciiLower converte para minúsculas sem acentos (para emails)
+func asciiLower(s string) string {
+	replacer := strings.NewReplacer("á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e",
+		"í", "i", "ó", "o", "ô", "o", "õ", "o", "ú", "u", "ü", "u", "ç", "c")
+	return replacer.Replace(strings.ToLower(s))
+}
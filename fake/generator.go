@@ -0,0 +1,318 @@
+// Package fake gera registros fictícios, mas válidos, para os campos de um schema:
+// CPFs e CNPJs com dígitos verificadores corretos, CEPs, telefones, emails, nomes e
+// endereços brasileiros, respeitando tipo, obrigatoriedade, enum, máscara e regex.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-crud-generator/models"
+)
+
+// Tentativas de gerar um valor que passe nas regex e, em campos unique, ainda não usado
+const maxAttempts = 50
+
+// Chance de um campo opcional ficar vazio (NULL)
+const emptyOptionalPercent = 15
+
+// Generator gera registros para um schema. Com a mesma semente, gera os mesmos registros.
+type Generator struct {
+	rnd    *rand.Rand
+	schema *models.Schema
+	now    time.Time                  // Referência das datas (fixa, para a saída ser reproduzível)
+	used   map[string]map[string]bool // Valores já gerados dos campos unique e das chaves
+}
+
+// NewGenerator cria um gerador para o schema com a semente informada
+func NewGenerator(schema *models.Schema, seed int64) *Generator {
+	return &Generator{
+		rnd:    rand.New(rand.NewSource(seed)),
+		schema: schema,
+		now:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		used:   map[string]map[string]bool{},
+	}
+}
+
+// Record gera um registro no formato de um formulário enviado (como o que
+// validators.ValidateData recebe). Chaves geradas e uploads ficam de fora.
+func (g *Generator) Record() (url.Values, error) {
+	p := g.newPerson()
+	form := url.Values{}
+	for _, field := range g.schema.Fields {
+		if g.schema.IsGeneratedKey(field) || field.IsUpload() {
+			continue
+		}
+		if !field.Required && !field.PrimaryKey && field.Type != "bool" && g.rnd.Intn(100) < emptyOptionalPercent {
+			form.Set(field.Name, "")
+			continue
+		}
+
+		value, err := g.uniqueValue(field, p)
+		if err != nil {
+			return nil, fmt.Errorf("campo %s: %w", field.Name, err)
+		}
+		form.Set(field.Name, value)
+	}
+	return form, nil
+}
+
+// uniqueValue gera o valor do campo, repetindo enquanto ele já tiver sido usado em
+// campos unique ou de chave
+func (g *Generator) uniqueValue(field models.Field, p person) (string, error) {
+	if !field.Unique && !field.PrimaryKey {
+		return g.value(field, p)
+	}
+	used := g.used[field.Name]
+	if used == nil {
+		used = map[string]bool{}
+		g.used[field.Name] = used
+	}
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		// Nas novas tentativas muda a pessoa, para nomes e emails variarem
+		value, err := g.value(field, p)
+		if err != nil {
+			return "", err
+		}
+		if !used[value] {
+			used[value] = true
+			return value, nil
+		}
+		p = g.newPerson()
+	}
+	return "", fmt.Errorf("não foi possível gerar um valor ainda não usado (poucas opções para unique)")
+}
+
+// value gera um valor para o campo, no formato do formulário
+func (g *Generator) value(field models.Field, p person) (string, error) {
+	if len(field.Enum) > 0 {
+		return g.pick(field.Enum), nil
+	}
+
+	switch field.BaseType() {
+	case "int":
+		return strconv.Itoa(g.intValue(field)), nil
+	case "bigint":
+		return strconv.FormatInt(int64(g.intValue(field))*1000+int64(g.rnd.Intn(1000)), 10), nil
+	case "float":
+		return strconv.FormatFloat(float64(g.rnd.Intn(100000))/100, 'f', 2, 64), nil
+	case "decimal":
+		return g.decimal(field)
+	case "bool":
+		return strconv.Itoa(g.rnd.Intn(2)), nil
+	case "date":
+		return g.date(field).Format("2006-01-02"), nil
+	case "datetime":
+		return g.now.Add(-time.Duration(g.rnd.Intn(365*24*60)) * time.Minute).Format("2006-01-02T15:04"), nil
+	case "time":
+		return fmt.Sprintf("%02d:%02d", 8+g.rnd.Intn(11), g.rnd.Intn(60)), nil
+	case "uuid":
+		return g.uuid(), nil
+	case "ulid":
+		return g.ulid(), nil
+	case "json":
+		raw, _ := json.Marshal(map[string]interface{}{"descricao": g.Words(3), "quantidade": g.rnd.Intn(100)})
+		return string(raw), nil
+	case "text":
+		return g.text(field, g.Words(12+g.rnd.Intn(20))+".")
+	}
+	return g.stringValue(field, p)
+}
+
+// stringValue escolhe o valor de um campo string: pelo tipo de validação, pelo nome do
+// campo (nome, endereço, cidade...), pelas regex, pela máscara ou texto genérico, nessa ordem
+func (g *Generator) stringValue(field models.Field, p person) (string, error) {
+	candidates := []func() string{}
+	switch field.Validation.Type {
+	case "cpf":
+		candidates = append(candidates, g.CPF)
+	case "cnpj":
+		candidates = append(candidates, g.CNPJ)
+	case "cep":
+		candidates = append(candidates, func() string { return g.cep(p.city) })
+	case "telefone":
+		candidates = append(candidates, func() string { return g.phone(p.city) })
+	case "email":
+		candidates = append(candidates, func() string { return g.email(p) })
+	}
+	if byName := g.byFieldName(field.Name, p); byName != nil {
+		candidates = append(candidates, byName)
+	}
+
+	for _, candidate := range candidates {
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			if value := candidate(); g.fits(field, value) {
+				return value, nil
+			}
+		}
+	}
+
+	if rules := field.Validation.RegexRules; len(rules) > 0 {
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			value, err := g.FromRegex(rules[attempt%len(rules)].Pattern)
+			if err != nil {
+				return "", err
+			}
+			if g.fits(field, value) {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("nenhum valor gerado satisfaz todas as regex_rules")
+	}
+
+	if field.Mask != "" {
+		return g.fromMask(field.Mask), nil
+	}
+	return g.text(field, g.Words(2+g.rnd.Intn(3)))
+}
+
+// byFieldName reconhece campos comuns pelas partes do nome (ex.: nome_cliente, cidade_entrega)
+func (g *Generator) byFieldName(name string, p person) func() string {
+	for _, part := range strings.Split(strings.ToLower(name), "_") {
+		switch part {
+		case "cpf":
+			return g.CPF
+		case "cnpj":
+			return g.CNPJ
+		case "cep":
+			return func() string { return g.cep(p.city) }
+		case "telefone", "fone", "celular", "tel", "whatsapp":
+			return func() string { return g.phone(p.city) }
+		case "email", "mail":
+			return func() string { return g.email(p) }
+		case "nome", "name", "cliente", "responsavel", "contato":
+			return func() string { return p.first + " " + p.last }
+		case "sobrenome":
+			return func() string { return p.last }
+		case "empresa", "razao", "fantasia":
+			return func() string { return g.company(p) }
+		case "endereco", "logradouro", "rua":
+			return g.street
+		case "numero", "num":
+			return func() string { return strconv.Itoa(1 + g.rnd.Intn(2999)) }
+		case "complemento":
+			return func() string { return "Apto " + strconv.Itoa(11+g.rnd.Intn(190)) }
+		case "bairro":
+			return func() string { return g.pick(neighborhoods) }
+		case "cidade", "municipio":
+			return func() string { return p.city.name }
+		case "estado", "uf":
+			return func() string { return p.city.uf }
+		case "rg":
+			return func() string { return g.digits(9) }
+		}
+	}
+	return nil
+}
+
+// fits confere as regex_rules e o tamanho máximo do campo (como a validação do servidor)
+func (g *Generator) fits(field models.Field, value string) bool {
+	for _, rule := range field.Validation.RegexRules {
+		if matched, _ := regexp.MatchString(rule.Pattern, value); !matched {
+			return false
+		}
+	}
+	return field.BaseType() != "string" || utf8.RuneCountInString(value) <= field.MaxLength()
+}
+
+// fromMask preenche a máscara: 9 dígito, # letra, * letra ou dígito; o resto é literal
+func (g *Generator) fromMask(mask string) string {
+	var sb strings.Builder
+	for _, r := range mask {
+		switch r {
+		case '9':
+			sb.WriteByte(byte('0' + g.rnd.Intn(10)))
+		case '#':
+			sb.WriteByte(byte('A' + g.rnd.Intn(26)))
+		case '*':
+			sb.WriteByte("ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"[g.rnd.Intn(36)])
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// text corta o texto no tamanho máximo do campo e confere as regex_rules
+func (g *Generator) text(field models.Field, value string) (string, error) {
+	if field.BaseType() == "string" {
+		if runes := []rune(value); len(runes) > field.MaxLength() {
+			value = strings.TrimSpace(string(runes[:field.MaxLength()]))
+		}
+	}
+	if !g.fits(field, value) {
+		return "", fmt.Errorf("o texto gerado não satisfaz as regex_rules")
+	}
+	return value, nil
+}
+
+// intValue gera inteiros: idades para campos "idade", senão de 1 a 1000 (chaves até 1.000.000)
+func (g *Generator) intValue(field models.Field) int {
+	if strings.Contains(strings.ToLower(field.Name), "idade") {
+		return 18 + g.rnd.Intn(70)
+	}
+	if field.PrimaryKey || field.Unique {
+		return 1 + g.rnd.Intn(1000000)
+	}
+	return 1 + g.rnd.Intn(1000)
+}
+
+// decimal gera um valor com a escala do campo e até 6 dígitos inteiros
+func (g *Generator) decimal(field models.Field) (string, error) {
+	precision, scale, err := field.DecimalSpec()
+	if err != nil {
+		return "", err
+	}
+	intDigits := min(precision-scale, 6)
+	value := "0"
+	if intDigits > 0 {
+		value = strconv.Itoa(g.rnd.Intn(pow10(intDigits)))
+	}
+	if scale > 0 {
+		value += "." + g.digits(scale)
+	}
+	return value, nil
+}
+
+// date gera datas de nascimento (18 a 80 anos atrás) para campos "nascimento", senão dos últimos 3 anos
+func (g *Generator) date(field models.Field) time.Time {
+	if strings.Contains(strings.ToLower(field.Name), "nasc") {
+		return g.now.AddDate(-18-g.rnd.Intn(62), 0, -g.rnd.Intn(365))
+	}
+	return g.now.AddDate(0, 0, -g.rnd.Intn(3*365))
+}
+
+// uuid gera um UUID versão 4 com o gerador da semente (models.NewUUID não é reproduzível)
+func (g *Generator) uuid() string {
+	b := make([]byte, 16)
+	g.rnd.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// ulid gera um ULID com o gerador da semente
+func (g *Generator) ulid() string {
+	const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	b := make([]byte, 26)
+	b[0] = crockford[g.rnd.Intn(8)] // O primeiro caractere só vai até 7 (128 bits)
+	for i := 1; i < len(b); i++ {
+		b[i] = crockford[g.rnd.Intn(32)]
+	}
+	return string(b)
+}
+
+func pow10(n int) int {
+	result := 1
+	for ; n > 0; n-- {
+		result *= 10
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package fake
+
+import (
+	"reflect"
+	"testing"
+
+	"go-crud-generator/models"
+	"go-crud-generator/validators"
+)
+
+// fakeTestSchema tem um campo de cada tipo e os campos reconhecidos pelo nome e pela validação
+func fakeTestSchema() *models.Schema {
+	return &models.Schema{
+		TableName: "clientes",
+		Fields: []models.Field{
+			{Name: "id", Type: "int", PrimaryKey: true},
+			{Name: "codigo", Type: "uuid", Unique: true},
+			{Name: "nome", Type: "string", Required: true},
+			{Name: "cpf", Type: "string", Required: true, Unique: true, Mask: "999.999.999-99", Validation: models.Validation{Type: "cpf"}},
+			{Name: "cnpj_empresa", Type: "string", Mask: "99.999.999/9999-99", Validation: models.Validation{Type: "cnpj"}},
+			{Name: "email", Type: "string", Validation: models.Validation{Type: "email"}},
+			{Name: "telefone", Type: "string", Mask: "(99) 99999-9999", Validation: models.Validation{Type: "telefone"}},
+			{Name: "cep", Type: "string", Mask: "99999-999", Validation: models.Validation{Type: "cep"}},
+			{Name: "cidade", Type: "string"},
+			{Name: "uf", Type: "string", Length: 2},
+			{Name: "placa", Type: "string", Required: true, Validation: models.Validation{
+				RegexRules: []models.RegexRule{{Pattern: `^[A-Z]{3}\d[A-Z]\d{2}$`, Message: "Placa inválida"}},
+			}},
+			{Name: "status", Type: "string", Enum: []string{"ativo", "inativo"}},
+			{Name: "idade", Type: "int"},
+			{Name: "pontos", Type: "bigint"},
+			{Name: "saldo", Type: "decimal(8,3)"},
+			{Name: "taxa", Type: "float"},
+			{Name: "vip", Type: "bool"},
+			{Name: "nascimento", Type: "date"},
+			{Name: "ultimo_acesso", Type: "datetime"},
+			{Name: "horario", Type: "time"},
+			{Name: "rastreio", Type: "ulid"},
+			{Name: "extra", Type: "json"},
+			{Name: "obs", Type: "text"},
+			{Name: "foto", Type: "image"},
+		},
+	}
+}
+
+func TestCPFAndCNPJAreValid(t *testing.T) {
+	// Documentos conhecidos: confirmam o cálculo dos dígitos que o gerador usa
+	if !validators.IsValidCPF("529.982.247-25", true) || validators.IsValidCPF("529.982.247-26", true) {
+		t.Fatal("validators.IsValidCPF não confere o CPF de referência")
+	}
+	if !validators.IsValidCNPJ("11.222.333/0001-81", true) || validators.IsValidCNPJ("11.222.333/0001-80", true) {
+		t.Fatal("validators.IsValidCNPJ não confere o CNPJ de referência")
+	}
+
+	g := NewGenerator(fakeTestSchema(), 1)
+	for i := 0; i < 5000; i++ {
+		if cpf := g.CPF(); !validators.IsValidCPF(cpf, true) {
+			t.Fatalf("CPF gerado inválido: %s", cpf)
+		}
+		if cnpj := g.CNPJ(); !validators.IsValidCNPJ(cnpj, true) {
+			t.Fatalf("CNPJ gerado inválido: %s", cnpj)
+		}
+	}
+}
+
+// Os registros gerados passam pela mesma validação do formulário
+func TestRecordsPassValidation(t *testing.T) {
+	schema := fakeTestSchema()
+	g := NewGenerator(schema, 7)
+	cpfs := map[string]bool{}
+	for i := 0; i < 500; i++ {
+		form, err := g.Record()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, errs := validators.ValidateData(form, schema)
+		if len(errs) > 0 {
+			t.Fatalf("registro %d inválido: %v\n%v", i+1, errs, form)
+		}
+		if _, ok := form["id"]; ok {
+			t.Fatal("a chave auto-increment não deveria ser gerada")
+		}
+		if _, ok := form["foto"]; ok {
+			t.Fatal("campos de upload não deveriam ser gerados")
+		}
+		cpf := data["cpf"].(string)
+		if cpfs[cpf] {
+			t.Errorf("CPF %s repetido em campo unique", cpf)
+		}
+		cpfs[cpf] = true
+	}
+}
+
+func TestSeedIsDeterministic(t *testing.T) {
+	generate := func(seed int64) []map[string][]string {
+		g := NewGenerator(fakeTestSchema(), seed)
+		records := []map[string][]string{}
+		for i := 0; i < 200; i++ {
+			form, err := g.Record()
+			if err != nil {
+				t.Fatal(err)
+			}
+			records = append(records, form)
+		}
+		return records
+	}
+
+	first := generate(42)
+	if again := generate(42); !reflect.DeepEqual(first, again) {
+		t.Error("a mesma semente gerou registros diferentes")
+	}
+	if other := generate(43); reflect.DeepEqual(first, other) {
+		t.Error("sementes diferentes geraram os mesmos registros")
+	}
+}
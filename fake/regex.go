@@ -0,0 +1,98 @@
+package fake
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// Repetições extras geradas para *, + e {n,}
+const maxExtraRepeat = 3
+
+// FromRegex gera um texto que casa com a expressão regular (sintaxe do pacote regexp)
+func (g *Generator) FromRegex(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := g.writeRegex(&sb, re.Simplify()); err != nil {
+		return "", fmt.Errorf("regex %q: %w", pattern, err)
+	}
+	return sb.String(), nil
+}
+
+func (g *Generator) writeRegex(sb *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return nil
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		r, ok := g.classRune(re.Rune)
+		if !ok {
+			return fmt.Errorf("classe de caracteres vazia")
+		}
+		sb.WriteRune(r)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteByte(byte('a' + g.rnd.Intn(26)))
+	case syntax.OpCapture:
+		return g.writeRegex(sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := g.writeRegex(sb, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		return g.writeRegex(sb, re.Sub[g.rnd.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, maxExtraRepeat
+		case syntax.OpPlus:
+			min, max = 1, 1+maxExtraRepeat
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + maxExtraRepeat
+		}
+		for n := min + g.rnd.Intn(max-min+1); n > 0; n-- {
+			if err := g.writeRegex(sb, re.Sub[0]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("construção não suportada (%v)", re.Op)
+	}
+	return nil
+}
+
+// classRune sorteia um caractere da classe (pares de intervalos [lo, hi]), preferindo
+// caracteres ASCII visíveis quando a classe os inclui (ex.: [^0-9])
+func (g *Generator) classRune(ranges []rune) (rune, bool) {
+	printable := []rune{}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], '!'), min(ranges[i+1], '~')
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) < 2 {
+		return 0, false
+	}
+
+	i := 2 * g.rnd.Intn(len(ranges)/2)
+	lo, hi := ranges[i], ranges[i+1]
+	if hi-lo > unicode.MaxASCII {
+		hi = lo + unicode.MaxASCII
+	}
+	return lo + rune(g.rnd.Intn(int(hi-lo)+1)), true
+}
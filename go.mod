@@ -3,6 +3,9 @@ module go-crud-generator
 go 1.22.2
 
 require (
-	filippo.io/edwards25519 v1.1.0 // indirect
-	github.com/go-sql-driver/mysql v1.8.1 // indirect
+	github.com/BurntSushi/toml v1.6.0
+	github.com/go-sql-driver/mysql v1.8.1
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require filippo.io/edwards25519 v1.1.0 // indirect
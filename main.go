@@ -1,95 +1,150 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"html/template"
-	"log"
-	"net/http"
+	"io"
 	"os"
+	"strings"
+
 	"go-crud-generator/config"
-	"go-crud-generator/controllers"
-	"go-crud-generator/models"
 )
 
+// command é um subcomando da CLI (crud-app <comando> [opções])
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int // Retorna o código de saída
+}
+
+// commands lista os subcomandos na ordem exibida pela ajuda
+var commands = []command{
+	{"serve", "Sobe o servidor web do CRUD", runServe},
+	{"migrate", "Aplica (ou desfaz com --rollback) a migração do schema no banco", runMigrate},
+	{"validate-schema", "Valida o schema sem conectar ao banco", runValidateSchema},
+	{"convert-schema", "Converte o schema entre JSON, YAML e TOML", runConvertSchema},
+	{"introspect", "Gera o schema de uma tabela existente", runIntrospect},
+	{"seed", "Insere registros de fixtures (JSON, YAML ou CSV) ou gera registros fictícios", runSeed},
+	{"export", "Exporta os registros da tabela em CSV ou JSON", runExport},
+	{"import", "Importa registros de um arquivo CSV ou JSON", runImport},
+	{"openapi", "Gera a especificação OpenAPI das rotas do CRUD", runOpenAPI},
+	{"config", "Mostra a configuração efetiva (config print)", runConfig},
+}
+
 func main() {
-	// 1. Carregar Configuração (CLI args ou ENV)
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Erro ao carregar configuração: %v\n\n", err)
-		fmt.Println("Uso:")
-		fmt.Println("  ./crud-app [opções]")
-		fmt.Println("\nOpções:")
-		fmt.Println("  --db-host      string   Host do banco de dados (padrão: localhost)")
-		fmt.Println("  --db-port      string   Porta do banco de dados (padrão: 3306)")
-		fmt.Println("  --db-user      string   Usuário do banco de dados (obrigatório)")
-		fmt.Println("  --db-psw       string   Senha do banco de dados")
-		fmt.Println("  --db-name      string   Nome do banco de dados (obrigatório)")
-		fmt.Println("  --port         string   Porta da aplicação (padrão: 8080)")
-		fmt.Println("  --json-schema  string   Caminho do arquivo JSON schema (obrigatório)")
-		fmt.Println("\nExemplo:")
-		fmt.Println("  ./crud-app --db-host localhost --db-port 3306 --db-user root --db-psw secret --db-name mydb --port 8080 --json-schema schema.json")
-		fmt.Println("\nAlternativamente, você pode usar variáveis de ambiente:")
-		fmt.Println("  DB_HOST, DB_PORT, DB_USER, DB_PSW, DB_NAME, PORT, JSON_SCHEMA")
-		os.Exit(1)
-	}
+	args := os.Args[1:]
 
-	// Exibir configuração carregada
-	log.Println("=== Configuração Carregada ===")
-	log.Printf("DB Host:     %s", cfg.DBHost)
-	log.Printf("DB Port:     %s", cfg.DBPort)
-	log.Printf("DB User:     %s", cfg.DBUser)
-	log.Printf("DB Password: %s", maskPassword(cfg.DBPassword))
-	log.Printf("DB Name:     %s", cfg.DBName)
-	log.Printf("App Port:    %s", cfg.Port)
-	log.Printf("JSON Schema: %s", cfg.JSONSchemaPath)
-	log.Println("==============================")
-
-	// 2. Carregar Schema JSON
-	schema, err := models.LoadSchema(cfg.JSONSchemaPath)
-	if err != nil {
-		log.Fatalf("❌ Erro ao carregar schema JSON: %v", err)
+	// Sem comando (ou só com opções): serve, como nas versões anteriores
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") && !isHelpArg(args[0]) {
+		os.Exit(runServe(args))
 	}
-	log.Println("✅ Schema JSON carregado com sucesso.")
 
-	// 3. Conectar ao Banco de Dados
-	db, err := config.InitDB(cfg)
-	if err != nil {
-		log.Fatalf("❌ Erro ao conectar ao banco de dados: %v", err)
+	if args[0] == "help" || isHelpArg(args[0]) {
+		if len(args) > 1 {
+			if cmd := findCommand(args[1]); cmd != nil {
+				os.Exit(cmd.run([]string{"-h"}))
+			}
+		}
+		printUsage(os.Stdout)
+		os.Exit(0)
 	}
-	defer db.Close()
-	log.Println("✅ Conexão com MySQL estabelecida.")
 
-	// 4. Auto-Migrate: Criar tabela se não existir
-	if err := models.AutoMigrate(db, schema); err != nil {
-		log.Fatalf("❌ Erro ao executar migração automática: %v", err)
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "❌ Comando desconhecido: %s\n\n", args[0])
+		printUsage(os.Stderr)
+		os.Exit(2)
 	}
-	log.Printf("✅ Tabela '%s' garantida.", schema.TableName)
+	os.Exit(cmd.run(args[1:]))
+}
 
-	// 5. Inicializar Camadas
-	repo := models.NewDynamicRepository(db, schema)
+func isHelpArg(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
 
-	// Carregar e parsear o template HTML
-	tmpl, err := template.ParseFiles("views/templates/crud.html")
-	if err != nil {
-		log.Fatalf("❌ Erro ao parsear template: %v", err)
+// findCommand busca um subcomando pelo nome (nil se não existir)
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
 	}
+	return nil
+}
 
-	// 6. Configurar Controllers e Rotas
-	crudController := controllers.NewCRUDController(repo, schema, tmpl)
+// printUsage exibe a ajuda geral com a lista de comandos
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Uso:")
+	fmt.Fprintln(w, "  ./crud-app <comando> [opções]")
+	fmt.Fprintln(w, "\nComandos:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nUse \"./crud-app <comando> -h\" para ver as opções de cada comando.")
+	fmt.Fprintln(w, "Sem comando, ./crud-app [opções] equivale a ./crud-app serve [opções].")
+	fmt.Fprintln(w, "\nAs opções também podem vir de variáveis de ambiente ou de um arquivo de configuração")
+	fmt.Fprintln(w, "(--config, com perfis escolhidos por --profile). Variáveis lidas:")
+	fmt.Fprintln(w, wrapList(config.EnvVars(), "  ", 80))
+}
 
-	mux := http.NewServeMux()
-	crudController.RegisterRoutes(mux)
+// wrapList junta os itens separados por vírgula, quebrando as linhas em width colunas
+func wrapList(items []string, indent string, width int) string {
+	var sb strings.Builder
+	line := indent
+	for i, item := range items {
+		if i < len(items)-1 {
+			item += ","
+		}
+		if len(line)+len(item)+1 > width && line != indent {
+			sb.WriteString(strings.TrimRight(line, " ") + "\n")
+			line = indent
+		}
+		line += item + " "
+	}
+	sb.WriteString(strings.TrimRight(line, " "))
+	return sb.String()
+}
 
-	// Servir arquivos estáticos
-	fs := http.FileServer(http.Dir("./static"))
-	mux.Handle("/static/", http.StripPrefix("/static/", fs))
+// newCommandFlags cria o FlagSet de um comando com a ajuda padrão: uso, descrição e
+// as opções registradas (geradas pelo próprio FlagSet)
+func newCommandFlags(name, usage, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Uso:\n  ./crud-app %s %s\n\n%s\n", name, usage, description)
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(out, "\nOpções:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
 
-	// 7. Iniciar Servidor
-	log.Printf("🚀 Servidor iniciado na porta :%s", cfg.Port)
-	log.Printf("📍 Acesse: http://localhost:%s", cfg.Port)
-	if err := http.ListenAndServe(":"+cfg.Port, mux); err != nil {
-		log.Fatalf("❌ Erro ao iniciar servidor: %v", err)
+// loadCommandConfig faz o parse das opções do comando (config.Load). Se o comando não
+// deve continuar (ajuda pedida ou configuração inválida), retorna nil e o código de saída.
+func loadCommandConfig(fs *flag.FlagSet, args []string, opts config.Options) (*config.Config, int) {
+	cfg, err := config.Load(fs, args, opts)
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao carregar configuração: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Use \"./crud-app %s -h\" para ver as opções.\n", fs.Name())
+		return nil, 2
+	}
+	if err != nil {
+		return nil, parseExitCode(err) // O FlagSet já exibiu o erro de parse e a ajuda
 	}
+	return cfg, 0
+}
+
+// parseExitCode traduz o erro de fs.Parse no código de saída (0 quando a ajuda foi pedida)
+func parseExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	return 2
 }
 
 // maskPassword mascara a senha para exibição segura
@@ -102,3 +157,11 @@ func maskPassword(password string) string {
 	}
 	return password[:2] + "***" + password[len(password)-1:]
 }
+
+// describeStorage descreve o backend de uploads para o log de inicialização
+func describeStorage(cfg *config.Config) string {
+	if cfg.Storage == "s3" {
+		return fmt.Sprintf("s3 (%s, bucket %s)", cfg.S3Endpoint, cfg.S3Bucket)
+	}
+	return fmt.Sprintf("local (%s)", cfg.UploadDir)
+}
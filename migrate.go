@@ -0,0 +1,114 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"go-crud-generator/config"
+	"go-crud-generator/models"
+)
+
+// runMigrate implementa "crud-app migrate": deixa a tabela de acordo com o schema
+// (--dry-run só mostra os comandos) ou desfaz a última migração aplicada (--rollback)
+func runMigrate(args []string) int {
+	fs := newCommandFlags("migrate", "[--dry-run] [--rollback [--force]] [opções]",
+		"Cria a tabela do schema ou adiciona as colunas que faltam, registrando o que foi feito\n"+
+			"em "+models.MigrationsTable+" para poder ser desfeito com --rollback.")
+	dryRun := fs.Bool("dry-run", false, "Só exibe os comandos, sem alterar o banco")
+	rollback := fs.Bool("rollback", false, "Desfaz a última migração aplicada na tabela do schema")
+	force := fs.Bool("force", false, "Com --rollback, permite desfazer a criação da tabela (DROP TABLE, apaga os registros)")
+	cfg, code := loadCommandConfig(fs, args, config.WithDB|config.WithSchema)
+	if cfg == nil {
+		return code
+	}
+
+	schema, err := models.LoadSchema(cfg.JSONSchemaPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao carregar schema: %v\n", err)
+		return 1
+	}
+
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao conectar ao banco de dados: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if *rollback {
+		return rollbackMigration(db, schema, *dryRun, *force)
+	}
+
+	if *dryRun {
+		plan, err := models.DryRunMigration(db, schema)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		if len(plan) == 0 {
+			fmt.Printf("✅ Tabela '%s' já está de acordo com o schema.\n", schema.TableName)
+			return 0
+		}
+		fmt.Printf("📋 %d comando(s) seriam executados:\n", len(plan))
+		for _, step := range plan {
+			fmt.Printf("   %s\n", step.Up)
+		}
+		return 0
+	}
+
+	applied, err := models.AutoMigrate(db, schema)
+	for _, step := range applied {
+		fmt.Printf("   %s\n", step.Up)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	if len(applied) == 0 {
+		fmt.Printf("✅ Tabela '%s' já está de acordo com o schema.\n", schema.TableName)
+		return 0
+	}
+	fmt.Printf("✅ Migração aplicada na tabela '%s' (%d comando(s)).\n", schema.TableName, len(applied))
+	return 0
+}
+
+// rollbackMigration desfaz (ou, em dry-run, só exibe) a última migração da tabela.
+// Desfazer a criação da tabela exige force.
+func rollbackMigration(db *sql.DB, schema *models.Schema, dryRun, force bool) int {
+	var queries []string
+	var err error
+	if dryRun {
+		queries, err = models.PlanRollback(db, schema, force)
+	} else {
+		queries, err = models.RollbackMigration(db, schema, force)
+	}
+	var dropTable *models.DropTableError
+	errors.As(err, &dropTable) // Em dry-run, os comandos são exibidos com um aviso
+	switch {
+	case errors.Is(err, models.ErrNoMigration):
+		fmt.Fprintf(os.Stderr, "❌ Nenhuma migração registrada para a tabela '%s'.\n", schema.TableName)
+		return 1
+	case dropTable != nil && !dryRun:
+		fmt.Fprintf(os.Stderr, "❌ Desfazer a última migração apaga a tabela '%s' e seus %d registro(s). Use --force para confirmar.\n", dropTable.Table, dropTable.Rows)
+		return 1
+	case err != nil && dropTable == nil:
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	if dryRun {
+		fmt.Printf("📋 %d comando(s) seriam executados:\n", len(queries))
+	}
+	for _, query := range queries {
+		fmt.Printf("   %s\n", query)
+	}
+	if dropTable != nil {
+		fmt.Printf("⚠️  Apaga a tabela '%s' e seus %d registro(s): o rollback exigirá --force.\n", dropTable.Table, dropTable.Rows)
+	}
+	if !dryRun {
+		fmt.Printf("✅ Última migração da tabela '%s' desfeita.\n", schema.TableName)
+	}
+	return 0
+}
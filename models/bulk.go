@@ -0,0 +1,137 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// keysWhere monta a condição que seleciona os registros das chaves: "id IN (?, ?)" com
+// chave simples, "(a = ? AND b = ?) OR (...)" com chave composta
+func (r *DynamicRepository) keysWhere(keys []Key) (string, []interface{}, error) {
+	if len(keys) == 0 {
+		return "", nil, fmt.Errorf("nenhum registro selecionado")
+	}
+
+	fields := r.schema.PrimaryKeyFields()
+	args := []interface{}{}
+	if len(fields) == 1 {
+		placeholders := make([]string, len(keys))
+		for i, key := range keys {
+			if len(key) != 1 {
+				return "", nil, fmt.Errorf("chave primária deve ter 1 valor, recebeu %d", len(key))
+			}
+			placeholders[i] = "?"
+			args = append(args, key[0])
+		}
+		return fmt.Sprintf("%s IN (%s)", fields[0].Name, strings.Join(placeholders, ", ")), args, nil
+	}
+
+	conditions := make([]string, len(keys))
+	for i, key := range keys {
+		where, err := r.keyWhere(key)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions[i] = "(" + where + ")"
+		args = append(args, key...)
+	}
+	return strings.Join(conditions, " OR "), args, nil
+}
+
+// ForEachKey percorre os registros das chaves informadas, em ordem de chave primária
+// (chaves que não existem mais são ignoradas). Para no primeiro erro retornado por fn.
+func (r *DynamicRepository) ForEachKey(keys []Key, fn func(row map[string]interface{}) error) error {
+	where, args, err := r.keysWhere(keys)
+	if err != nil {
+		return err
+	}
+	order, _ := r.orderBy("", false)
+
+	rows, err := r.queryRows("SELECT * FROM "+r.schema.TableName+" WHERE "+where+order, args...)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteMany remove os registros das chaves numa única transação e retorna os registros
+// removidos (por exemplo, para apagar os arquivos deles). Chaves que não existem mais são
+// ignoradas.
+func (r *DynamicRepository) DeleteMany(keys []Key) ([]map[string]interface{}, error) {
+	where, args, err := r.keysWhere(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []map[string]interface{}
+	err = r.inTransaction(func(tx *sql.Tx) error {
+		// Trava as linhas para a lista devolvida ser exatamente a que foi removida
+		rows, err := tx.Query("SELECT * FROM "+r.schema.TableName+" WHERE "+where+" FOR UPDATE", args...)
+		if err != nil {
+			return err
+		}
+		deleted, err = scanRows(rows, r.schema)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("DELETE FROM "+r.schema.TableName+" WHERE "+where, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// UpdateMany grava value no campo de todos os registros das chaves numa única transação
+// (a versão do lock otimista, se houver, é incrementada em todos) e retorna quantos
+// registros existiam. Um valor repetido em campo unique desfaz tudo e retorna *DuplicateError.
+func (r *DynamicRepository) UpdateMany(keys []Key, field string, value interface{}) (int, error) {
+	target, ok := r.schema.FieldByName(field)
+	if !ok || target.PrimaryKey {
+		return 0, fmt.Errorf("o campo %q não pode ser alterado em massa", field)
+	}
+	where, args, err := r.keysWhere(keys)
+	if err != nil {
+		return 0, err
+	}
+
+	set := fmt.Sprintf("%s = ?", target.Name)
+	if versionField := r.schema.VersionField; versionField != "" {
+		set += fmt.Sprintf(", %s = %s + 1", versionField, versionField)
+	}
+
+	matched := 0
+	err = r.inTransaction(func(tx *sql.Tx) error {
+		// Conta as linhas encontradas: RowsAffected ignora as que já tinham o valor
+		if err := tx.QueryRow("SELECT COUNT(*) FROM "+r.schema.TableName+" WHERE "+where+" FOR UPDATE", args...).Scan(&matched); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE "+r.schema.TableName+" SET "+set+" WHERE "+where, append([]interface{}{value}, args...)...)
+		return r.duplicateError(err)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return matched, nil
+}
+
+// inTransaction executa fn numa transação, confirmada se fn não retornar erro
+func (r *DynamicRepository) inTransaction(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
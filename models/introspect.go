@@ -0,0 +1,276 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Nomes de coluna tratados como versão do lock otimista (ver Schema.VersionField)
+var versionColumnNames = []string{"version", "versao"}
+
+// columnInfo é uma linha de information_schema.COLUMNS
+type columnInfo struct {
+	name       string
+	dataType   string // ex.: varchar
+	columnType string // ex.: varchar(14), tinyint(1), enum('PF','PJ')
+	nullable   bool
+	primaryKey bool
+	extra      string // ex.: auto_increment
+	length     sql.NullInt64
+	precision  sql.NullInt64
+	scale      sql.NullInt64
+	comment    string
+}
+
+// IntrospectTable monta um schema a partir da definição da tabela no banco atual
+// (information_schema): tipos, obrigatoriedade, chaves, tamanhos, índices unique e de busca,
+// além de sugerir validação e máscara pelo nome da coluna (cpf, cnpj, cep, telefone, email).
+// Retorna também avisos sobre o que não pôde ser representado fielmente.
+func IntrospectTable(db *sql.DB, table string) (*Schema, []string, error) {
+	columns, err := tableColumns(db, table)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao ler colunas de %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return nil, nil, fmt.Errorf("tabela %q não encontrada no banco atual", table)
+	}
+
+	uniques, composite, err := uniqueColumns(db, table)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao ler índices de %s: %w", table, err)
+	}
+
+	searchable, err := indexColumns(db, table, SearchIndex)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao ler índices de %s: %w", table, err)
+	}
+
+	schema := &Schema{TableName: table}
+	warnings := []string{}
+	for _, index := range composite {
+		warnings = append(warnings, fmt.Sprintf("índice unique composto %s não é representado no schema (só unique por campo)", index))
+	}
+
+	primaryKeys := 0
+	for _, col := range columns {
+		if col.primaryKey {
+			primaryKeys++
+		}
+	}
+
+	for _, col := range columns {
+		fieldType, enum, warning := fieldTypeFromColumn(col)
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+
+		// Coluna de versão: vira version_field em vez de campo do formulário
+		if schema.VersionField == "" && !col.primaryKey && fieldType == "int" && containsFold(versionColumnNames, col.name) {
+			schema.VersionField = col.name
+			continue
+		}
+
+		field := Field{
+			Name:       col.name,
+			Type:       fieldType,
+			PrimaryKey: col.primaryKey,
+			Required:   !col.nullable && !col.primaryKey && fieldType != "bool",
+			Enum:       enum,
+			Label:      col.comment,
+			Unique:     uniques[col.name],
+			Searchable: containsFold(searchable, col.name),
+		}
+		if fieldType == "string" && col.length.Valid && col.length.Int64 != defaultStringLength {
+			field.Length = int(col.length.Int64)
+		}
+
+		if fieldType == "string" && len(enum) == 0 {
+			field.Validation.Type, field.Mask = suggestValidation(col.name)
+		}
+
+		if col.primaryKey && (fieldType == "int" || fieldType == "bigint") {
+			autoIncrement := strings.Contains(col.extra, "auto_increment")
+			if primaryKeys == 1 && !autoIncrement {
+				warnings = append(warnings, fmt.Sprintf("chave %s não é AUTO_INCREMENT, mas chaves inteiras simples são tratadas como auto-incremento", col.name))
+			}
+		}
+
+		schema.Fields = append(schema.Fields, field)
+	}
+
+	if primaryKeys == 0 {
+		warnings = append(warnings, "a tabela não tem chave primária; defina primary_key em algum campo antes de usar o schema")
+	}
+	return schema, warnings, nil
+}
+
+// tableColumns lê as colunas da tabela, na ordem da tabela
+func tableColumns(db *sql.DB, table string) ([]columnInfo, error) {
+	rows, err := db.Query(`
+		SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, EXTRA,
+		       CHARACTER_MAXIMUM_LENGTH, NUMERIC_PRECISION, NUMERIC_SCALE, COLUMN_COMMENT
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := []columnInfo{}
+	for rows.Next() {
+		var col columnInfo
+		var nullable, key string
+		if err := rows.Scan(&col.name, &col.dataType, &col.columnType, &nullable, &key, &col.extra,
+			&col.length, &col.precision, &col.scale, &col.comment); err != nil {
+			return nil, err
+		}
+		col.dataType = strings.ToLower(col.dataType)
+		col.columnType = strings.ToLower(col.columnType)
+		col.nullable = nullable == "YES"
+		col.primaryKey = key == "PRI"
+		col.extra = strings.ToLower(col.extra)
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+// uniqueColumns retorna as colunas com índice unique próprio e os nomes dos índices
+// unique compostos (várias colunas), que não têm representação no schema
+func uniqueColumns(db *sql.DB, table string) (map[string]bool, []string, error) {
+	rows, err := db.Query(`
+		SELECT INDEX_NAME, COLUMN_NAME
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND NON_UNIQUE = 0 AND INDEX_NAME <> 'PRIMARY'
+		ORDER BY INDEX_NAME, SEQ_IN_INDEX`, table)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	indexes := map[string][]string{}
+	order := []string{}
+	for rows.Next() {
+		var index, column string
+		if err := rows.Scan(&index, &column); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := indexes[index]; !ok {
+			order = append(order, index)
+		}
+		indexes[index] = append(indexes[index], column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	uniques := map[string]bool{}
+	composite := []string{}
+	for _, index := range order {
+		if cols := indexes[index]; len(cols) == 1 {
+			uniques[cols[0]] = true
+		} else {
+			composite = append(composite, fmt.Sprintf("%s (%s)", index, strings.Join(cols, ", ")))
+		}
+	}
+	return uniques, composite, nil
+}
+
+// fieldTypeFromColumn traduz o tipo MySQL da coluna para um tipo do schema (o inverso de
+// mapJSONTypeToSQL). Colunas enum viram string com a lista de valores.
+func fieldTypeFromColumn(col columnInfo) (fieldType string, enum []string, warning string) {
+	switch col.dataType {
+	case "tinyint":
+		if strings.HasPrefix(col.columnType, "tinyint(1)") {
+			return "bool", nil, ""
+		}
+		return "int", nil, ""
+	case "smallint", "mediumint", "int", "integer", "year":
+		return "int", nil, ""
+	case "bigint":
+		return "bigint", nil, ""
+	case "decimal", "numeric":
+		return fmt.Sprintf("decimal(%d,%d)", col.precision.Int64, col.scale.Int64), nil, ""
+	case "float", "double", "real":
+		return "float", nil, ""
+	case "char":
+		// Chaves CHAR(36)/CHAR(26) são UUIDs/ULIDs gerados pela aplicação
+		if col.primaryKey && col.length.Int64 == 36 {
+			return "uuid", nil, ""
+		}
+		if col.primaryKey && col.length.Int64 == 26 {
+			return "ulid", nil, ""
+		}
+		return "string", nil, ""
+	case "varchar":
+		return "string", nil, ""
+	case "tinytext", "text", "mediumtext", "longtext":
+		return "text", nil, ""
+	case "date":
+		return "date", nil, ""
+	case "datetime", "timestamp":
+		return "datetime", nil, ""
+	case "time":
+		return "time", nil, ""
+	case "json":
+		return "json", nil, ""
+	case "enum":
+		return "string", parseEnumValues(col.columnType), ""
+	}
+	return "text", nil, fmt.Sprintf("coluna %s: tipo %s não suportado, mapeado para text; revise", col.name, col.columnType)
+}
+
+// parseEnumValues extrai os valores de "enum('a','b')" (aspas simples dentro dos valores vêm em dobro)
+func parseEnumValues(columnType string) []string {
+	inner := strings.TrimSuffix(strings.TrimPrefix(columnType, "enum("), ")")
+	values := []string{}
+	var current strings.Builder
+	inQuote := false
+	for i := 0; i < len(inner); i++ {
+		ch := inner[i]
+		switch {
+		case ch == '\'' && inQuote && i+1 < len(inner) && inner[i+1] == '\'':
+			current.WriteByte('\'')
+			i++
+		case ch == '\'':
+			if inQuote {
+				values = append(values, current.String())
+				current.Reset()
+			}
+			inQuote = !inQuote
+		case inQuote:
+			current.WriteByte(ch)
+		}
+	}
+	return values
+}
+
+// suggestValidation sugere validation.type e máscara pelas partes do nome da coluna
+// (ex.: cpf, cpf_cliente, telefone_celular, email_contato)
+func suggestValidation(column string) (validationType, mask string) {
+	for _, part := range strings.Split(strings.ToLower(column), "_") {
+		switch part {
+		case "cpf":
+			return "cpf", "999.999.999-99"
+		case "cnpj":
+			return "cnpj", "99.999.999/9999-99"
+		case "cep":
+			return "cep", "99999-999"
+		case "telefone", "fone", "celular", "tel":
+			return "telefone", "(99) 99999-9999"
+		case "email", "mail":
+			return "email", ""
+		}
+	}
+	return "", ""
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
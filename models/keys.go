@@ -0,0 +1,163 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Alfabeto Base32 de Crockford usado pelos ULIDs
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	ulidRegex = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`)
+)
+
+// NewUUID gera um UUID versão 4 (aleatório)
+func NewUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("falha ao gerar UUID: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // Versão 4
+	b[8] = (b[8] & 0x3f) | 0x80 // Variante RFC 4122
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// NewULID gera um ULID: 48 bits de timestamp em ms + 80 bits aleatórios,
+// codificados em 26 caracteres Base32 de Crockford (ordenáveis por tempo)
+func NewULID() string {
+	var b [16]byte
+	ms := uint64(time.Now().UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic(fmt.Sprintf("falha ao gerar ULID: %v", err))
+	}
+
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+
+	out := make([]byte, 26)
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
+
+// Key é o valor da chave primária de um registro: um valor por campo
+// primary_key, na ordem em que aparecem no schema
+type Key []interface{}
+
+// PrimaryKeyFields retorna os campos que compõem a chave primária, na ordem do schema
+func (s *Schema) PrimaryKeyFields() []Field {
+	fields := []Field{}
+	for _, field := range s.Fields {
+		if field.PrimaryKey {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// IsGeneratedKey indica se o campo é uma chave primária cujo valor é gerado
+// pelo banco (int AUTO_INCREMENT, apenas em chaves simples) ou pelo servidor (uuid, ulid)
+func (s *Schema) IsGeneratedKey(field Field) bool {
+	if !field.PrimaryKey {
+		return false
+	}
+	switch field.Type {
+	case "int", "bigint":
+		return len(s.PrimaryKeyFields()) == 1
+	case "uuid", "ulid":
+		return true
+	}
+	return false
+}
+
+// generateKey gera o valor de uma chave primária uuid/ulid no momento do INSERT.
+// Retorna nil para chaves auto-increment ou informadas pelo usuário.
+func generateKey(field Field) interface{} {
+	switch field.Type {
+	case "uuid":
+		return NewUUID()
+	case "ulid":
+		return NewULID()
+	}
+	return nil
+}
+
+// KeyOf extrai a chave primária de um registro
+func (s *Schema) KeyOf(row map[string]interface{}) Key {
+	key := Key{}
+	for _, field := range s.PrimaryKeyFields() {
+		key = append(key, row[field.Name])
+	}
+	return key
+}
+
+// KeyQuery codifica a chave primária de um registro como query string,
+// um parâmetro por coluna (ex.: "id=1" ou "pedido_id=1&produto_id=2")
+func (s *Schema) KeyQuery(row map[string]interface{}) string {
+	values := url.Values{}
+	for _, field := range s.PrimaryKeyFields() {
+		if v := row[field.Name]; v != nil {
+			values.Set(field.Name, fmt.Sprint(v))
+		}
+	}
+	return values.Encode()
+}
+
+// ParseKey lê a chave primária de uma query string (um parâmetro por coluna)
+// e converte cada parte para o tipo do campo
+func (s *Schema) ParseKey(values url.Values) (Key, error) {
+	fields := s.PrimaryKeyFields()
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("nenhuma chave primária definida no schema")
+	}
+
+	key := make(Key, 0, len(fields))
+	for _, field := range fields {
+		value, err := parseKeyValue(field, values.Get(field.Name))
+		if err != nil {
+			return nil, err
+		}
+		key = append(key, value)
+	}
+	return key, nil
+}
+
+// parseKeyValue converte o valor textual de uma parte da chave para o tipo do campo
+func parseKeyValue(field Field, raw string) (interface{}, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("%s ausente", field.Name)
+	}
+
+	switch field.Type {
+	case "int", "bigint":
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s inválido: deve ser um número inteiro", field.Name)
+		}
+		return id, nil
+	case "uuid":
+		if !uuidRegex.MatchString(raw) {
+			return nil, fmt.Errorf("%s inválido: deve ser um UUID", field.Name)
+		}
+	case "ulid":
+		if !ulidRegex.MatchString(raw) {
+			return nil, fmt.Errorf("%s inválido: deve ser um ULID", field.Name)
+		}
+	}
+	return raw, nil
+}
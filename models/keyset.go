@@ -0,0 +1,285 @@
+package models
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CountMode define como FindPage conta os registros que atendem à busca
+type CountMode string
+
+const (
+	CountExact    CountMode = "exact"    // COUNT(*): exato, mas percorre a tabela (ou o índice) inteira
+	CountEstimate CountMode = "estimate" // Estimativa das estatísticas do MySQL, sem percorrer a tabela
+	CountNone     CountMode = "none"     // Não conta
+)
+
+// CountModes lista os valores aceitos em CountMode
+var CountModes = []string{string(CountExact), string(CountEstimate), string(CountNone)}
+
+// ErrInvalidCursor indica um cursor malformado ou gerado para outra ordenação
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// PageOptions descreve a página pedida a FindPage. After e Before são cursores
+// devolvidos em Page.Next e Page.Prev (no máximo um deles; nenhum pede a primeira página).
+type PageOptions struct {
+	Limit  int    // Registros por página
+	Search string // Texto buscado nos campos string/text ("" lista tudo)
+	Sort   string // Campo da ordenação ("" ordena pela chave primária)
+	Desc   bool   // Ordem decrescente
+	After  string // Registros depois do cursor
+	Before string // Registros antes do cursor
+	Count  CountMode
+}
+
+// Page é uma página da lista paginada por cursor
+type Page struct {
+	Rows      []map[string]interface{}
+	Next      string // Cursor da próxima página ("" se esta é a última)
+	Prev      string // Cursor da página anterior ("" se esta é a primeira)
+	Total     int    // Registros que atendem à busca (-1 com CountNone)
+	Estimated bool   // Total é uma estimativa (CountEstimate)
+}
+
+// cursor é a posição de um registro na ordenação: os valores das colunas de
+// keysetColumns, junto com a ordenação para a qual foi gerado
+type cursor struct {
+	Sort   string        `json:"s,omitempty"`
+	Desc   bool          `json:"d,omitempty"`
+	Values []interface{} `json:"v"`
+}
+
+// FindPage busca uma página usando a posição do último registro visto (keyset) em vez de
+// OFFSET: o custo não cresce com o número da página, desde que a ordenação tenha índice.
+// A chave primária desempata a ordenação, então cada registro aparece uma vez só.
+func (r *DynamicRepository) FindPage(opts PageOptions) (*Page, error) {
+	columns, err := r.keysetColumns(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	// Para voltar, a consulta anda na ordem inversa a partir do cursor e as linhas são
+	// desviradas no final
+	backward := opts.Before != ""
+	token := opts.After
+	if backward {
+		token = opts.Before
+	}
+	desc := opts.Desc != backward
+
+	where, searchArgs := r.searchWhere(opts.Search)
+	args := append([]interface{}{}, searchArgs...)
+	conditions := []string{}
+	if where != "" {
+		conditions = append(conditions, "("+where+")")
+	}
+	if token != "" {
+		values, err := decodeCursor(token, opts, len(columns))
+		if err != nil {
+			return nil, err
+		}
+		condition, conditionArgs := keysetCondition(columns, values, desc)
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	order := []string{}
+	for _, field := range columns {
+		order = append(order, field.Name+" "+direction)
+	}
+
+	query := "SELECT * FROM " + r.schema.TableName
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// Um registro a mais indica se existe outra página depois desta
+	query += fmt.Sprintf(" ORDER BY %s LIMIT %d", strings.Join(order, ", "), opts.Limit+1)
+
+	rows, err := r.queryRows(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(rows) > opts.Limit
+	if hasMore {
+		rows = rows[:opts.Limit]
+	}
+	if backward {
+		if !hasMore {
+			// Voltou até o início: devolve a primeira página inteira, e não só o que
+			// faltava antes do cursor
+			opts.Before = ""
+			return r.FindPage(opts)
+		}
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	page := &Page{Rows: rows, Total: -1}
+	if len(rows) > 0 {
+		if hasMore || backward {
+			page.Next = encodeCursor(columns, rows[len(rows)-1], opts)
+		}
+		if token != "" {
+			page.Prev = encodeCursor(columns, rows[0], opts)
+		}
+	}
+
+	switch opts.Count {
+	case CountExact:
+		page.Total, err = r.countRecords(where, searchArgs)
+	case CountEstimate:
+		page.Total, err = r.estimateRecords(where, searchArgs)
+		page.Estimated = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// keysetColumns são as colunas que definem a posição de um registro: o campo da
+// ordenação (se houver) seguido da chave primária
+func (r *DynamicRepository) keysetColumns(sort string) ([]Field, error) {
+	columns := []Field{}
+	if sort != "" {
+		field, ok := r.schema.FieldByName(sort)
+		if !ok || !field.IsSortable() {
+			return nil, fmt.Errorf("não é possível ordenar por %q", sort)
+		}
+		columns = append(columns, field)
+	}
+	for _, field := range r.schema.PrimaryKeyFields() {
+		if field.Name != sort {
+			columns = append(columns, field)
+		}
+	}
+	return columns, nil
+}
+
+// keysetCondition seleciona os registros depois da posição values na ordenação:
+// (c1 > v1) OR (c1 = v1 AND c2 > v2) OR ... A forma expandida, em vez de
+// (c1, c2) > (v1, v2), trata os NULLs, que o MySQL põe antes dos demais valores
+// na ordem crescente (e depois, na decrescente).
+func keysetCondition(columns []Field, values []interface{}, desc bool) (string, []interface{}) {
+	alternatives := []string{}
+	args := []interface{}{}
+	for i, field := range columns {
+		terms := []string{}
+		termArgs := []interface{}{}
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				terms = append(terms, columns[j].Name+" IS NULL")
+			} else {
+				terms = append(terms, columns[j].Name+" = ?")
+				termArgs = append(termArgs, values[j])
+			}
+		}
+
+		nullable := !field.Required && !field.PrimaryKey
+		switch {
+		case values[i] == nil && desc:
+			continue // Nada vem depois de NULL na ordem decrescente
+		case values[i] == nil:
+			terms = append(terms, field.Name+" IS NOT NULL")
+		case desc && nullable:
+			terms = append(terms, "("+field.Name+" < ? OR "+field.Name+" IS NULL)")
+			termArgs = append(termArgs, values[i])
+		case desc:
+			terms = append(terms, field.Name+" < ?")
+			termArgs = append(termArgs, values[i])
+		default:
+			terms = append(terms, field.Name+" > ?")
+			termArgs = append(termArgs, values[i])
+		}
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+		args = append(args, termArgs...)
+	}
+	if len(alternatives) == 0 {
+		return "1 = 0", nil
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// encodeCursor gera o cursor (base64 de um JSON) com a posição do registro
+func encodeCursor(columns []Field, row map[string]interface{}, opts PageOptions) string {
+	c := cursor{Sort: opts.Sort, Desc: opts.Desc}
+	for _, field := range columns {
+		value := row[field.Name]
+		if t, ok := value.(time.Time); ok {
+			// Mesmo texto que o MySQL devolveu, para a comparação com a coluna
+			value = t.Format("2006-01-02 15:04:05.999999")
+		}
+		c.Values = append(c.Values, value)
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor lê os valores do cursor, conferindo que ele foi gerado para a mesma
+// ordenação e com o mesmo número de colunas
+func decodeCursor(token string, opts PageOptions, columns int) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // Chaves bigint não cabem em float64
+	var c cursor
+	if err := decoder.Decode(&c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != opts.Sort || c.Desc != opts.Desc || len(c.Values) != columns {
+		return nil, ErrInvalidCursor
+	}
+	for i, value := range c.Values {
+		switch v := value.(type) {
+		case json.Number:
+			c.Values[i] = v.String()
+		case map[string]interface{}, []interface{}:
+			return nil, ErrInvalidCursor
+		}
+	}
+	return c.Values, nil
+}
+
+// estimateRecords estima os registros que atendem à condição where sem contá-los: sem
+// busca usa o número de linhas das estatísticas da tabela e, com busca, a estimativa do
+// plano de execução (EXPLAIN)
+func (r *DynamicRepository) estimateRecords(where string, args []interface{}) (int, error) {
+	if where == "" {
+		var rows sql.NullInt64
+		err := r.db.QueryRow("SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", r.schema.TableName).Scan(&rows)
+		return int(rows.Int64), err
+	}
+
+	rows, err := r.db.Query("EXPLAIN SELECT * FROM "+r.schema.TableName+" WHERE "+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+	plan, err := scanRowToMap(rows, &Schema{})
+	if err != nil {
+		return 0, err
+	}
+	// rows é a estimativa de linhas lidas; filtered, a porcentagem delas que atende ao WHERE
+	estimate, _ := strconv.ParseFloat(fmt.Sprint(plan["rows"]), 64)
+	if filtered, err := strconv.ParseFloat(fmt.Sprint(plan["filtered"]), 64); err == nil {
+		estimate = estimate * filtered / 100
+	}
+	return int(estimate), nil
+}
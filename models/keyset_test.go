@@ -0,0 +1,170 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func keysetTestSchema() *Schema {
+	return &Schema{
+		TableName: "pedidos",
+		Fields: []Field{
+			{Name: "id", Type: "bigint", PrimaryKey: true},
+			{Name: "cliente", Type: "string", Required: true},
+			{Name: "entrega", Type: "date"}, // Opcional: pode ser NULL
+			{Name: "obs", Type: "text"},
+		},
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	columns := []Field{{Name: "entrega", Type: "datetime"}, {Name: "cliente"}, {Name: "id", PrimaryKey: true}}
+	row := map[string]interface{}{
+		"entrega": time.Date(2024, 3, 1, 8, 30, 0, 120000000, time.UTC),
+		"cliente": nil,
+		"id":      int64(9007199254740993), // Não cabe em float64
+	}
+	opts := PageOptions{Sort: "entrega", Desc: true}
+
+	token := encodeCursor(columns, row, opts)
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("cursor %q não é seguro para URL", token)
+	}
+	values, err := decodeCursor(token, opts, len(columns))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"2024-03-01 08:30:00.12", nil, "9007199254740993"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("decodeCursor = %#v, esperava %#v", values, want)
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	columns := []Field{{Name: "cliente"}, {Name: "id", PrimaryKey: true}}
+	opts := PageOptions{Sort: "cliente"}
+	valid := encodeCursor(columns, map[string]interface{}{"cliente": "Ana", "id": 3}, opts)
+	encode := func(json string) string { return base64.RawURLEncoding.EncodeToString([]byte(json)) }
+
+	tests := []struct {
+		name    string
+		token   string
+		opts    PageOptions
+		columns int
+	}{
+		{"base64 inválido", "não*é*base64", opts, 2},
+		{"JSON inválido", encode(`{"s":"cliente","v":[`), opts, 2},
+		{"cursor alterado", valid[:len(valid)-4] + "AAAA", opts, 2},
+		{"outra ordenação", valid, PageOptions{Sort: "entrega"}, 2},
+		{"outra direção", valid, PageOptions{Sort: "cliente", Desc: true}, 2},
+		{"sem ordenação", valid, PageOptions{}, 2},
+		{"colunas a menos", valid, opts, 3},
+		{"valor objeto", encode(`{"s":"cliente","v":[{"a":1},3]}`), opts, 2},
+		{"valor lista", encode(`{"s":"cliente","v":["Ana",[3]]}`), opts, 2},
+	}
+	for _, tt := range tests {
+		if _, err := decodeCursor(tt.token, tt.opts, tt.columns); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: erro = %v, esperava ErrInvalidCursor", tt.name, err)
+		}
+	}
+}
+
+func TestKeysetColumns(t *testing.T) {
+	repo := &DynamicRepository{schema: keysetTestSchema()}
+	names := func(sort string) []string {
+		columns, err := repo.keysetColumns(sort)
+		if err != nil {
+			t.Fatalf("keysetColumns(%q): %v", sort, err)
+		}
+		result := []string{}
+		for _, field := range columns {
+			result = append(result, field.Name)
+		}
+		return result
+	}
+	if got := names(""); !reflect.DeepEqual(got, []string{"id"}) {
+		t.Errorf("sem ordenação = %v", got)
+	}
+	if got := names("cliente"); !reflect.DeepEqual(got, []string{"cliente", "id"}) {
+		t.Errorf("por cliente = %v", got)
+	}
+	if got := names("id"); !reflect.DeepEqual(got, []string{"id"}) {
+		t.Errorf("pela chave = %v (a chave não se repete)", got)
+	}
+	for _, sort := range []string{"obs", "inexistente"} {
+		if _, err := repo.keysetColumns(sort); err == nil {
+			t.Errorf("keysetColumns(%q) deveria falhar", sort)
+		}
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	schema := keysetTestSchema()
+	field := func(name string) Field {
+		f, _ := schema.FieldByName(name)
+		return f
+	}
+	id, cliente, entrega := field("id"), field("cliente"), field("entrega")
+
+	tests := []struct {
+		name    string
+		columns []Field
+		values  []interface{}
+		desc    bool
+		sql     string
+		args    []interface{}
+	}{
+		{
+			name: "chave crescente", columns: []Field{id}, values: []interface{}{"5"},
+			sql: "((id > ?))", args: []interface{}{"5"},
+		},
+		{
+			name: "chave decrescente", columns: []Field{id}, values: []interface{}{"5"}, desc: true,
+			sql: "((id < ?))", args: []interface{}{"5"},
+		},
+		{
+			name: "duas colunas", columns: []Field{cliente, id}, values: []interface{}{"Ana", "5"},
+			sql:  "((cliente > ?) OR (cliente = ? AND id > ?))",
+			args: []interface{}{"Ana", "Ana", "5"},
+		},
+		{
+			name: "três colunas decrescente", columns: []Field{cliente, entrega, id}, values: []interface{}{"Ana", "2024-03-01", "5"}, desc: true,
+			sql:  "((cliente < ?) OR (cliente = ? AND (entrega < ? OR entrega IS NULL)) OR (cliente = ? AND entrega = ? AND id < ?))",
+			args: []interface{}{"Ana", "Ana", "2024-03-01", "Ana", "2024-03-01", "5"},
+		},
+		{
+			// NULLs vêm primeiro na ordem crescente: depois deles, todos os preenchidos
+			name: "NULL crescente", columns: []Field{entrega, id}, values: []interface{}{nil, "5"},
+			sql:  "((entrega IS NOT NULL) OR (entrega IS NULL AND id > ?))",
+			args: []interface{}{"5"},
+		},
+		{
+			// ...e por último na decrescente: depois deles, só os NULLs seguintes
+			name: "NULL decrescente", columns: []Field{entrega, id}, values: []interface{}{nil, "5"}, desc: true,
+			sql:  "((entrega IS NULL AND id < ?))",
+			args: []interface{}{"5"},
+		},
+		{
+			name: "anulável decrescente", columns: []Field{entrega, id}, values: []interface{}{"2024-03-01", "5"}, desc: true,
+			sql:  "(((entrega < ? OR entrega IS NULL)) OR (entrega = ? AND id < ?))",
+			args: []interface{}{"2024-03-01", "2024-03-01", "5"},
+		},
+		{
+			name: "nada depois", columns: []Field{entrega}, values: []interface{}{nil}, desc: true,
+			sql: "1 = 0",
+		},
+	}
+	for _, tt := range tests {
+		sql, args := keysetCondition(tt.columns, tt.values, tt.desc)
+		if sql != tt.sql {
+			t.Errorf("%s:\n sql = %s\n esperava %s", tt.name, sql, tt.sql)
+		}
+		if !reflect.DeepEqual(args, tt.args) && (len(args) != 0 || len(tt.args) != 0) {
+			t.Errorf("%s: args = %v, esperava %v", tt.name, args, tt.args)
+		}
+	}
+}
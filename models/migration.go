@@ -1,52 +1,395 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
 )
 
-// AutoMigrate cria a tabela no banco de dados com base no schema, se ela não existir
-func AutoMigrate(db *sql.DB, schema *Schema) error {
-	query := buildCreateTableQuery(schema)
+// MigrationsTable guarda o histórico das migrações aplicadas, usado pelo rollback
+const MigrationsTable = "schema_migrations"
 
-	_, err := db.Exec(query)
+// SearchIndex é o índice FULLTEXT com os campos "searchable", usado pela busca
+const SearchIndex = "ft_search"
+
+// ErrNoMigration indica que não há migração registrada para desfazer
+var ErrNoMigration = errors.New("nenhuma migração registrada para desfazer")
+
+// ErrDropTable indica que desfazer a migração apagaria a tabela, o que exige force
+var ErrDropTable = errors.New("desfazer a migração apaga a tabela")
+
+// DropTableError informa a tabela que o rollback apagaria e quantos registros ela tem
+type DropTableError struct {
+	Table string
+	Rows  int64
+}
+
+func (e *DropTableError) Error() string {
+	return fmt.Sprintf("desfazer a migração apaga a tabela %s (%d registro(s))", e.Table, e.Rows)
+}
+
+// Unwrap permite usar errors.Is(err, ErrDropTable)
+func (e *DropTableError) Unwrap() error {
+	return ErrDropTable
+}
+
+// MigrationStep é um comando da migração e o comando que o desfaz
+type MigrationStep struct {
+	Up   string
+	Down string
+}
+
+// AutoMigrate deixa a tabela do banco de acordo com o schema: cria a tabela se ela não
+// existir e adiciona as colunas que faltarem (colunas a mais ou com tipo diferente não são alteradas).
+// O índice de busca (SearchIndex) é criado, refeito ou removido conforme os campos "searchable".
+// Os passos executados são registrados em MigrationsTable para poderem ser desfeitos.
+func AutoMigrate(db *sql.DB, schema *Schema) ([]MigrationStep, error) {
+	plan, err := PlanMigration(db, schema)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan) == 0 {
+		return nil, nil
+	}
+
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	// DDL no MySQL não é transacional: em caso de falha, registra o que já foi aplicado
+	applied := []MigrationStep{}
+	for _, step := range plan {
+		if _, err := db.Exec(step.Up); err != nil {
+			if recordErr := recordMigration(db, schema.TableName, applied); recordErr != nil {
+				log.Printf("Erro ao registrar migração parcial: %v", recordErr)
+			}
+			return applied, fmt.Errorf("falha ao executar migração: %w. Query: %s", err, step.Up)
+		}
+		applied = append(applied, step)
+	}
+	return applied, recordMigration(db, schema.TableName, applied)
+}
+
+// PlanMigration calcula, sem executar, os comandos que AutoMigrate executaria
+func PlanMigration(db *sql.DB, schema *Schema) ([]MigrationStep, error) {
+	existing, err := existingColumns(db, schema.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler colunas de %s: %w", schema.TableName, err)
+	}
+
+	if len(existing) == 0 {
+		return []MigrationStep{{
+			Up:   buildCreateTableQuery(schema),
+			Down: fmt.Sprintf("DROP TABLE %s;", schema.TableName),
+		}}, nil
+	}
+
+	plan := []MigrationStep{}
+	for _, col := range columnDefinitions(schema) {
+		if !existing[strings.ToLower(col.name)] {
+			plan = append(plan, MigrationStep{
+				Up:   fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", schema.TableName, col.definition),
+				Down: fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", schema.TableName, col.name),
+			})
+		}
+	}
+
+	indexed, err := indexColumns(db, schema.TableName, SearchIndex)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler índices de %s: %w", schema.TableName, err)
+	}
+	return append(plan, searchIndexSteps(schema, indexed)...), nil
+}
+
+// searchIndexSteps compara as colunas do índice de busca no banco (indexed, vazio se ele
+// não existe) com os campos "searchable" do schema
+func searchIndexSteps(schema *Schema, indexed []string) []MigrationStep {
+	wanted := []string{}
+	for _, field := range schema.SearchableFields() {
+		wanted = append(wanted, field.Name)
+	}
+	if strings.EqualFold(strings.Join(wanted, ","), strings.Join(indexed, ",")) {
+		return nil
+	}
+
+	add := func(columns []string) string {
+		return fmt.Sprintf("ALTER TABLE %s ADD FULLTEXT INDEX %s (%s);", schema.TableName, SearchIndex, strings.Join(columns, ", "))
+	}
+	drop := fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;", schema.TableName, SearchIndex)
+	switch {
+	case len(indexed) == 0:
+		return []MigrationStep{{Up: add(wanted), Down: drop}}
+	case len(wanted) == 0:
+		return []MigrationStep{{Up: drop, Down: add(indexed)}}
+	}
+	// Colunas diferentes: o índice é refeito
+	return []MigrationStep{
+		{Up: drop, Down: add(indexed)},
+		{Up: add(wanted), Down: drop},
+	}
+}
+
+// indexColumns retorna as colunas do índice, na ordem do índice; vazio se ele não existir
+func indexColumns(db *sql.DB, table, index string) ([]string, error) {
+	rows, err := db.Query(
+		"SELECT COLUMN_NAME FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ? ORDER BY SEQ_IN_INDEX",
+		table, index,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+// PlanRollback retorna, sem executar, os comandos que desfazem a última migração da tabela.
+// Se eles apagam a tabela e force é falso, os comandos voltam junto com um *DropTableError.
+func PlanRollback(db *sql.DB, schema *Schema, force bool) ([]string, error) {
+	_, down, err := lastMigration(db, schema.TableName)
+	if err != nil {
+		return nil, err
+	}
+	return down, checkDropTable(db, schema.TableName, down, force)
+}
+
+// RollbackMigration desfaz a última migração registrada para a tabela do schema
+// (na ordem inversa) e a remove do histórico. Retorna os comandos executados.
+// Desfazer a criação da tabela (DROP TABLE) só é feito com force; sem ele, nada é
+// executado e o erro é um *DropTableError.
+func RollbackMigration(db *sql.DB, schema *Schema, force bool) ([]string, error) {
+	id, down, err := lastMigration(db, schema.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDropTable(db, schema.TableName, down, force); err != nil {
+		return nil, err
+	}
+
+	for _, query := range down {
+		if _, err := db.Exec(query); err != nil {
+			return nil, fmt.Errorf("falha ao desfazer migração: %w. Query: %s", err, query)
+		}
+	}
+
+	_, err = db.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", MigrationsTable), id)
+	return down, err
+}
+
+// checkDropTable recusa os comandos de desfazer que apagam a tabela, a menos que force
+func checkDropTable(db *sql.DB, table string, down []string, force bool) error {
+	if force {
+		return nil
+	}
+	for _, query := range down {
+		if !strings.HasPrefix(strings.ToUpper(query), "DROP TABLE ") {
+			continue
+		}
+		var rows int64
+		if err := db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", table)).Scan(&rows); err != nil {
+			return fmt.Errorf("falha ao contar registros de %s: %w", table, err)
+		}
+		return &DropTableError{Table: table, Rows: rows}
+	}
+	return nil
+}
+
+// ensureMigrationsTable cria a tabela de histórico se ela não existir
+func ensureMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id INT AUTO_INCREMENT NOT NULL,
+  table_name VARCHAR(64) NOT NULL,
+  up_sql TEXT NOT NULL,
+  down_sql TEXT NOT NULL,
+  applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;`, MigrationsTable))
 	if err != nil {
-		return fmt.Errorf("falha ao executar CREATE TABLE: %w. Query: %s", err, query)
+		return fmt.Errorf("falha ao criar %s: %w", MigrationsTable, err)
 	}
 	return nil
 }
 
-// buildCreateTableQuery constrói a string da query SQL
-func buildCreateTableQuery(schema *Schema) string {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", schema.TableName))
+// recordMigration registra os passos aplicados; os comandos de desfazer ficam em ordem inversa
+func recordMigration(db *sql.DB, table string, steps []MigrationStep) error {
+	if len(steps) == 0 {
+		return nil
+	}
 
-	var primaryKey string
-	definitions := []string{}
+	up := make([]string, len(steps))
+	down := make([]string, len(steps))
+	for i, step := range steps {
+		up[i] = step.Up
+		down[len(steps)-1-i] = step.Down
+	}
+	upJSON, _ := json.Marshal(up)
+	downJSON, _ := json.Marshal(down)
+
+	_, err := db.Exec(
+		fmt.Sprintf("INSERT INTO %s (table_name, up_sql, down_sql) VALUES (?, ?, ?)", MigrationsTable),
+		table, string(upJSON), string(downJSON),
+	)
+	return err
+}
+
+// lastMigration busca a migração mais recente da tabela no histórico
+func lastMigration(db *sql.DB, table string) (int64, []string, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return 0, nil, err
+	}
+
+	var id int64
+	var downJSON string
+	err := db.QueryRow(
+		fmt.Sprintf("SELECT id, down_sql FROM %s WHERE table_name = ? ORDER BY id DESC LIMIT 1", MigrationsTable),
+		table,
+	).Scan(&id, &downJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, ErrNoMigration
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var down []string
+	if err := json.Unmarshal([]byte(downJSON), &down); err != nil {
+		return 0, nil, fmt.Errorf("histórico de migração %d inválido: %w", id, err)
+	}
+	return id, down, nil
+}
+
+// DryRunMigration valida a definição completa da tabela no próprio servidor, criando e
+// descartando uma tabela temporária com ela, e retorna o plano que AutoMigrate executaria.
+// Nada é alterado nas tabelas reais.
+func DryRunMigration(db *sql.DB, schema *Schema) ([]MigrationStep, error) {
+	ctx := context.Background()
+
+	// Tabelas temporárias só existem na conexão que as criou
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	tmpName := "_dry_run_" + schema.TableName
+	query := buildCreateTableQueryNamed(schema, "CREATE TEMPORARY TABLE", tmpName)
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return nil, fmt.Errorf("definição da tabela rejeitada pelo banco: %w. Query: %s", err, query)
+	}
+	if _, err := conn.ExecContext(ctx, "DROP TEMPORARY TABLE "+tmpName); err != nil {
+		return nil, err
+	}
+
+	return PlanMigration(db, schema)
+}
+
+// existingColumns retorna as colunas (em minúsculas) da tabela no banco atual; vazio se a tabela não existir
+func existingColumns(db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.Query(
+		"SELECT COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		table,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols[strings.ToLower(name)] = true
+	}
+	return cols, rows.Err()
+}
+
+// columnDefinition é a definição SQL de uma coluna da tabela
+type columnDefinition struct {
+	name       string
+	definition string // ex.: "cpf VARCHAR(255) NOT NULL"
+}
+
+// columnDefinitions monta as definições de todas as colunas do schema, incluindo a de versão
+func columnDefinitions(schema *Schema) []columnDefinition {
+	cols := []columnDefinition{}
 
 	for _, field := range schema.Fields {
-		sqlType := mapJSONTypeToSQL(field.Type)
-		definition := fmt.Sprintf("  %s %s", field.Name, sqlType)
+		sqlType := mapJSONTypeToSQL(field)
+		definition := fmt.Sprintf("%s %s", field.Name, sqlType)
 
 		if field.PrimaryKey {
-			if field.Type == "int" {
+			if (field.Type == "int" || field.Type == "bigint") && schema.IsGeneratedKey(field) {
 				definition += " AUTO_INCREMENT"
 			}
-			primaryKey = field.Name
+			definition += " NOT NULL"
 		} else if field.Required {
 			definition += " NOT NULL"
 		} else {
 			definition += " NULL"
 		}
+		if field.Unique && !field.PrimaryKey {
+			definition += " UNIQUE"
+		}
 
-		definitions = append(definitions, definition)
+		cols = append(cols, columnDefinition{name: field.Name, definition: definition})
 	}
 
+	// Coluna de versão usada pelo lock otimista em Update
+	if schema.VersionField != "" {
+		cols = append(cols, columnDefinition{
+			name:       schema.VersionField,
+			definition: fmt.Sprintf("%s INT NOT NULL DEFAULT 1", schema.VersionField),
+		})
+	}
+
+	return cols
+}
+
+// buildCreateTableQuery constrói a string da query SQL
+func buildCreateTableQuery(schema *Schema) string {
+	return buildCreateTableQueryNamed(schema, "CREATE TABLE IF NOT EXISTS", schema.TableName)
+}
+
+// buildCreateTableQueryNamed constrói o CREATE TABLE com o comando e o nome de tabela informados
+func buildCreateTableQueryNamed(schema *Schema, command, tableName string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s (\n", command, tableName))
+
+	definitions := []string{}
+	for _, col := range columnDefinitions(schema) {
+		definitions = append(definitions, "  "+col.definition)
+	}
 	sb.WriteString(strings.Join(definitions, ",\n"))
 
-	if primaryKey != "" {
-		sb.WriteString(fmt.Sprintf(",\n  PRIMARY KEY (%s)", primaryKey))
+	primaryKeys := []string{}
+	for _, field := range schema.PrimaryKeyFields() {
+		primaryKeys = append(primaryKeys, field.Name)
+	}
+	if len(primaryKeys) > 0 {
+		sb.WriteString(fmt.Sprintf(",\n  PRIMARY KEY (%s)", strings.Join(primaryKeys, ", ")))
+	}
+
+	// Tabelas temporárias InnoDB não aceitam FULLTEXT; o dry-run valida as colunas sem o índice
+	searchable := []string{}
+	for _, field := range schema.SearchableFields() {
+		searchable = append(searchable, field.Name)
+	}
+	if len(searchable) > 0 && !strings.Contains(command, "TEMPORARY") {
+		sb.WriteString(fmt.Sprintf(",\n  FULLTEXT KEY %s (%s)", SearchIndex, strings.Join(searchable, ", ")))
 	}
 
 	sb.WriteString("\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;")
@@ -55,20 +398,40 @@ func buildCreateTableQuery(schema *Schema) string {
 }
 
 // mapJSONTypeToSQL traduz tipos do JSON para tipos SQL compatíveis com MySQL 5.7+ e 8.0+
-func mapJSONTypeToSQL(jsonType string) string {
-	switch jsonType {
+func mapJSONTypeToSQL(field Field) string {
+	switch field.BaseType() {
 	case "int":
 		return "INT"
+	case "bigint":
+		return "BIGINT"
 	case "string":
-		return "VARCHAR(255)"
+		return fmt.Sprintf("VARCHAR(%d)", field.MaxLength())
 	case "text": // Para campos maiores
 		return "TEXT"
 	case "date":
 		return "DATE"
 	case "datetime":
 		return "DATETIME"
+	case "time":
+		return "TIME"
 	case "float":
 		return "DECIMAL(10, 2)" // Padrão genérico
+	case "decimal":
+		precision, scale, err := field.DecimalSpec()
+		if err != nil {
+			precision, scale = defaultDecimalPrecision, defaultDecimalScale
+		}
+		return fmt.Sprintf("DECIMAL(%d, %d)", precision, scale)
+	case "bool":
+		return "TINYINT(1)"
+	case "json": // MySQL 5.7.8+
+		return "JSON"
+	case "uuid":
+		return "CHAR(36)"
+	case "ulid":
+		return "CHAR(26)"
+	case "file", "image": // Chave do arquivo no storage
+		return "VARCHAR(255)"
 	default:
 		return "VARCHAR(255)"
 	}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Relation é uma chave estrangeira entre outra tabela e a tabela do schema: nas relações
+// filhas (ChildRelations) a outra tabela aponta para a do schema; nas relações pai
+// (ParentRelations), a tabela do schema aponta para a outra
+type Relation struct {
+	Constraint string
+	Table      string   // A outra tabela (filha ou pai)
+	Columns    []string // Colunas da outra tabela
+	RefColumns []string // Colunas da tabela do schema, na mesma ordem
+}
+
+// ChildRows são os registros de uma tabela filha ligados a um registro
+type ChildRows struct {
+	Relation
+	TableColumns []string                 // Todas as colunas da tabela filha, na ordem da tabela
+	Rows         []map[string]interface{} // Valores como texto (a tabela filha não tem schema)
+	Total        int                      // Total de registros ligados (Rows traz no máximo o limite)
+}
+
+// ChildRelations lê em information_schema as chaves estrangeiras de outras tabelas do
+// banco atual que apontam para a tabela do schema
+func (r *DynamicRepository) ChildRelations() ([]Relation, error) {
+	rows, err := r.db.Query(`
+		SELECT CONSTRAINT_NAME, TABLE_NAME, COLUMN_NAME, REFERENCED_COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE REFERENCED_TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME = ?
+		ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION`, r.schema.TableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	relations := []Relation{}
+	for rows.Next() {
+		var constraint, table, column, refColumn string
+		if err := rows.Scan(&constraint, &table, &column, &refColumn); err != nil {
+			return nil, err
+		}
+		last := len(relations) - 1
+		if last < 0 || relations[last].Table != table || relations[last].Constraint != constraint {
+			relations = append(relations, Relation{Constraint: constraint, Table: table})
+			last++
+		}
+		relations[last].Columns = append(relations[last].Columns, column)
+		relations[last].RefColumns = append(relations[last].RefColumns, refColumn)
+	}
+	return relations, rows.Err()
+}
+
+// ParentRelations lê em information_schema as chaves estrangeiras da tabela do schema que
+// apontam para outras tabelas do banco atual
+func (r *DynamicRepository) ParentRelations() ([]Relation, error) {
+	rows, err := r.db.Query(`
+		SELECT CONSTRAINT_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME, COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY CONSTRAINT_NAME, ORDINAL_POSITION`, r.schema.TableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	relations := []Relation{}
+	for rows.Next() {
+		var constraint, table, column, ownColumn string
+		if err := rows.Scan(&constraint, &table, &column, &ownColumn); err != nil {
+			return nil, err
+		}
+		last := len(relations) - 1
+		if last < 0 || relations[last].Constraint != constraint {
+			relations = append(relations, Relation{Constraint: constraint, Table: table})
+			last++
+		}
+		relations[last].Columns = append(relations[last].Columns, column)
+		relations[last].RefColumns = append(relations[last].RefColumns, ownColumn)
+	}
+	return relations, rows.Err()
+}
+
+// ReferenceExists indica se a tabela pai de rel tem o registro apontado pelos valores das
+// colunas do schema em values (todas precisam estar preenchidas)
+func (r *DynamicRepository) ReferenceExists(rel Relation, values map[string]interface{}) (bool, error) {
+	conditions := make([]string, len(rel.Columns))
+	args := make([]interface{}, len(rel.Columns))
+	for i, column := range rel.Columns {
+		conditions[i] = quoteIdent(column) + " = ?"
+		args[i] = values[rel.RefColumns[i]]
+	}
+	query := "SELECT 1 FROM " + quoteIdent(rel.Table) + " WHERE " + strings.Join(conditions, " AND ") + " LIMIT 1"
+
+	var found int
+	err := r.db.QueryRow(query, args...).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// FindChildren busca até limit registros da tabela filha que apontam para record, em
+// ordem de chave primária. Um registro com a chave referenciada nula não tem filhos.
+func (r *DynamicRepository) FindChildren(rel Relation, record map[string]interface{}, limit int) (*ChildRows, error) {
+	children := &ChildRows{Relation: rel, Rows: []map[string]interface{}{}}
+
+	conditions := make([]string, len(rel.Columns))
+	args := make([]interface{}, len(rel.Columns))
+	for i, column := range rel.Columns {
+		value, ok := record[rel.RefColumns[i]]
+		if !ok {
+			return nil, fmt.Errorf("coluna %s referenciada por %s não está no registro", rel.RefColumns[i], rel.Constraint)
+		}
+		if value == nil {
+			return children, nil
+		}
+		conditions[i] = quoteIdent(column) + " = ?"
+		args[i] = value
+	}
+	table := quoteIdent(rel.Table)
+	where := strings.Join(conditions, " AND ")
+
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE "+where, args...).Scan(&children.Total); err != nil {
+		return nil, err
+	}
+	if children.Total == 0 {
+		return children, nil
+	}
+
+	order, err := r.childOrder(rel.Table)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(fmt.Sprintf("SELECT * FROM %s WHERE %s%s LIMIT %d", table, where, order, limit), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if children.TableColumns, err = rows.Columns(); err != nil {
+		return nil, err
+	}
+	empty := &Schema{} // Sem campos: os valores ficam como texto
+	for rows.Next() {
+		row, err := scanRowToMap(rows, empty)
+		if err != nil {
+			return nil, err
+		}
+		children.Rows = append(children.Rows, row)
+	}
+	return children, rows.Err()
+}
+
+// childOrder monta o ORDER BY pela chave primária da tabela filha (vazio se ela não tiver)
+func (r *DynamicRepository) childOrder(table string) (string, error) {
+	rows, err := r.db.Query(`
+		SELECT COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND CONSTRAINT_NAME = 'PRIMARY'
+		ORDER BY ORDINAL_POSITION`, table)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns := []string{}
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return "", err
+		}
+		columns = append(columns, quoteIdent(column))
+	}
+	if err := rows.Err(); err != nil || len(columns) == 0 {
+		return "", err
+	}
+	return " ORDER BY " + strings.Join(columns, ", "), nil
+}
+
+// quoteIdent protege um nome de tabela ou coluna vindo do banco (não do schema, que
+// já é validado) para uso direto no SQL
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
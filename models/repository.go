@@ -2,10 +2,56 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-sql-driver/mysql"
 )
 
+// ErrConflict indica que o registro foi alterado por outra pessoa desde que foi carregado
+var ErrConflict = errors.New("registro alterado por outro usuário")
+
+// ConflictError carrega os valores atuais do registro quando o lock otimista falha
+type ConflictError struct {
+	Current map[string]interface{}
+}
+
+func (e *ConflictError) Error() string {
+	return ErrConflict.Error()
+}
+
+// Unwrap permite usar errors.Is(err, ErrConflict)
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// ErrVersionRequired indica uma atualização sem a versão carregada pelo cliente num schema
+// com VersionField: sem ela o lock otimista não teria como detectar a alteração concorrente
+var ErrVersionRequired = errors.New("versão do registro obrigatória")
+
+// ErrDuplicate indica que o valor de uma coluna unique (ou da chave) já existe em outro registro
+var ErrDuplicate = errors.New("valor já cadastrado")
+
+// DuplicateError informa o campo cujo valor já está cadastrado ("" se não identificado)
+type DuplicateError struct {
+	Field string
+}
+
+func (e *DuplicateError) Error() string {
+	return ErrDuplicate.Error()
+}
+
+// Unwrap permite usar errors.Is(err, ErrDuplicate)
+func (e *DuplicateError) Unwrap() error {
+	return ErrDuplicate
+}
+
 // DynamicRepository lida com operações CRUD para a entidade definida no schema
 type DynamicRepository struct {
 	db     *sql.DB
@@ -17,15 +63,29 @@ func NewDynamicRepository(db *sql.DB, schema *Schema) *DynamicRepository {
 	return &DynamicRepository{db: db, schema: schema}
 }
 
-// Create insere um novo registro
-func (r *DynamicRepository) Create(data map[string]interface{}) (int64, error) {
+// Create insere um novo registro e retorna o valor da chave primária.
+// Chaves int simples são AUTO_INCREMENT, uuid/ulid são geradas aqui e as demais vêm em data.
+func (r *DynamicRepository) Create(data map[string]interface{}) (Key, error) {
 	cols := []string{}
 	placeholders := []string{}
 	values := []interface{}{}
+	row := map[string]interface{}{} // Valores da chave conhecidos antes do INSERT
+	autoIncrement := ""
 
 	for _, field := range r.schema.Fields {
-		if field.PrimaryKey { // Pula PK (assumindo auto-increment)
-			continue
+		if field.PrimaryKey {
+			if (field.Type == "int" || field.Type == "bigint") && r.schema.IsGeneratedKey(field) { // Pula PK auto-increment
+				autoIncrement = field.Name
+				continue
+			}
+			if generated := generateKey(field); generated != nil {
+				cols = append(cols, field.Name)
+				placeholders = append(placeholders, "?")
+				values = append(values, generated)
+				row[field.Name] = generated
+				continue
+			}
+			row[field.Name] = data[field.Name]
 		}
 		if val, ok := data[field.Name]; ok {
 			cols = append(cols, field.Name)
@@ -42,27 +102,103 @@ func (r *DynamicRepository) Create(data map[string]interface{}) (int64, error) {
 
 	stmt, err := r.db.Prepare(query)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	defer stmt.Close()
 
 	res, err := stmt.Exec(values...)
 	if err != nil {
-		return 0, err
+		return nil, r.duplicateError(err)
 	}
 
-	return res.LastInsertId()
+	if autoIncrement != "" {
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		row[autoIncrement] = id
+	}
+	return r.schema.KeyOf(row), nil
 }
 
-// Update atualiza um registro existente
-func (r *DynamicRepository) Update(id interface{}, data map[string]interface{}) error {
+// CreateMany insere vários registros com um único INSERT (tudo ou nada). Como em Create,
+// chaves auto-increment ficam com o banco e uuid/ulid são geradas aqui; campos ausentes
+// de um registro recebem o DEFAULT da coluna.
+func (r *DynamicRepository) CreateMany(rows []map[string]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	cols := []Field{}
+	for _, field := range r.schema.Fields {
+		if field.PrimaryKey && (field.Type == "int" || field.Type == "bigint") && r.schema.IsGeneratedKey(field) {
+			continue
+		}
+		if field.PrimaryKey && generateKey(field) != nil {
+			cols = append(cols, field)
+			continue
+		}
+		for _, data := range rows {
+			if _, ok := data[field.Name]; ok {
+				cols = append(cols, field)
+				break
+			}
+		}
+	}
+
+	names := make([]string, len(cols))
+	for i, field := range cols {
+		names[i] = field.Name
+	}
+	tuples := make([]string, len(rows))
+	values := []interface{}{}
+	for i, data := range rows {
+		placeholders := make([]string, len(cols))
+		for j, field := range cols {
+			val, ok := data[field.Name]
+			if field.PrimaryKey {
+				if generated := generateKey(field); generated != nil {
+					val, ok = generated, true
+				}
+			}
+			if !ok {
+				placeholders[j] = "DEFAULT"
+				continue
+			}
+			placeholders[j] = "?"
+			values = append(values, val)
+		}
+		tuples[i] = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		r.schema.TableName,
+		strings.Join(names, ", "),
+		strings.Join(tuples, ", "),
+	)
+	if _, err := r.db.Exec(query, values...); err != nil {
+		return r.duplicateError(err)
+	}
+	return nil
+}
+
+// Update atualiza um registro existente com semântica de PATCH: só os campos presentes em
+// data são gravados (um valor nil grava NULL) e os demais ficam como estão.
+// Se o schema define VersionField, data precisa trazer a versão carregada pelo cliente
+// (senão retorna ErrVersionRequired): a escrita só acontece se ela ainda for a atual,
+// caso contrário retorna *ConflictError com os valores atuais do registro, ou
+// sql.ErrNoRows se o registro foi removido. A versão é incrementada a cada escrita.
+func (r *DynamicRepository) Update(key Key, data map[string]interface{}) error {
 	cols := []string{}
 	values := []interface{}{}
-	var pkName string
+
+	where, err := r.keyWhere(key)
+	if err != nil {
+		return err
+	}
 
 	for _, field := range r.schema.Fields {
-		if field.PrimaryKey {
-			pkName = field.Name
+		if field.PrimaryKey { // A chave não é alterada
 			continue
 		}
 		if val, ok := data[field.Name]; ok {
@@ -71,16 +207,27 @@ func (r *DynamicRepository) Update(id interface{}, data map[string]interface{})
 		}
 	}
 
-	if pkName == "" {
-		return fmt.Errorf("nenhuma chave primária definida no schema")
+	versionField := r.schema.VersionField
+	if len(cols) == 0 && versionField == "" {
+		return nil // Nada a alterar
 	}
 
-	values = append(values, id) // Adiciona o ID no final para o WHERE
+	values = append(values, key...) // Adiciona a chave no final para o WHERE
+
+	if versionField != "" {
+		version, ok := data[versionField]
+		if !ok || version == nil {
+			return ErrVersionRequired
+		}
+		cols = append(cols, fmt.Sprintf("%s = %s + 1", versionField, versionField))
+		where += fmt.Sprintf(" AND %s = ?", versionField)
+		values = append(values, version)
+	}
 
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?",
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
 		r.schema.TableName,
 		strings.Join(cols, ", "),
-		pkName,
+		where,
 	)
 
 	stmt, err := r.db.Prepare(query)
@@ -89,25 +236,37 @@ func (r *DynamicRepository) Update(id interface{}, data map[string]interface{})
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(values...)
-	return err
-}
+	res, err := stmt.Exec(values...)
+	if err != nil {
+		return r.duplicateError(err)
+	}
 
-// Delete remove um registro
-func (r *DynamicRepository) Delete(id interface{}) error {
-	pkName := ""
-	for _, field := range r.schema.Fields {
-		if field.PrimaryKey {
-			pkName = field.Name
-			break
+	if versionField != "" {
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			// Nenhuma linha casou: o registro sumiu (sql.ErrNoRows) ou a versão mudou
+			current, err := r.FindByID(key)
+			if err != nil {
+				return err
+			}
+			return &ConflictError{Current: current}
 		}
 	}
 
-	if pkName == "" {
-		return fmt.Errorf("nenhuma chave primária definida no schema")
+	return nil
+}
+
+// Delete remove um registro
+func (r *DynamicRepository) Delete(key Key) error {
+	where, err := r.keyWhere(key)
+	if err != nil {
+		return err
 	}
 
-	query := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", r.schema.TableName, pkName)
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", r.schema.TableName, where)
 
 	stmt, err := r.db.Prepare(query)
 	if err != nil {
@@ -115,97 +274,318 @@ func (r *DynamicRepository) Delete(id interface{}) error {
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(id)
+	_, err = stmt.Exec(key...)
 	return err
 }
 
-// FindByID busca um registro pelo ID
-func (r *DynamicRepository) FindByID(id interface{}) (map[string]interface{}, error) {
-	pkName := ""
-	for _, field := range r.schema.Fields {
-		if field.PrimaryKey {
-			pkName = field.Name
-			break
-		}
-	}
-	if pkName == "" {
-		return nil, fmt.Errorf("nenhuma chave primária definida no schema")
+// FindByID busca um registro pela chave primária
+func (r *DynamicRepository) FindByID(key Key) (map[string]interface{}, error) {
+	where, err := r.keyWhere(key)
+	if err != nil {
+		return nil, err
 	}
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE %s = ?", r.schema.TableName, pkName)
-	rows, err := r.db.Query(query, id)
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s", r.schema.TableName, where)
+	rows, err := r.db.Query(query, key...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	if rows.Next() {
-		return scanRowToMap(rows)
+		return scanRowToMap(rows, r.schema)
 	}
 	return nil, sql.ErrNoRows
 }
 
-// FindAll busca todos os registros com paginação e busca
-func (r *DynamicRepository) FindAll(page, limit int, search string) ([]map[string]interface{}, int, error) {
-	var query strings.Builder
-	var countQuery strings.Builder
-	args := []interface{}{}
+// ValueTaken indica se outro registro já tem value no campo (para avisar antes de gravar
+// um campo unique ou a chave). except, se informada, é a chave do registro em edição, que
+// não conta.
+func (r *DynamicRepository) ValueTaken(field string, value interface{}, except Key) (bool, error) {
+	query := fmt.Sprintf("SELECT 1 FROM %s WHERE %s = ?", r.schema.TableName, field)
+	args := []interface{}{value}
+	if except != nil {
+		where, err := r.keyWhere(except)
+		if err != nil {
+			return false, err
+		}
+		query += " AND NOT (" + where + ")"
+		args = append(args, except...)
+	}
 
-	query.WriteString("SELECT * FROM ")
-	query.WriteString(r.schema.TableName)
-	countQuery.WriteString("SELECT COUNT(*) FROM ")
-	countQuery.WriteString(r.schema.TableName)
-
-	// Clausula WHERE para busca
-	if search != "" {
-		whereClause := []string{}
-		searchLike := fmt.Sprintf("%%%s%%", search)
-		for _, field := range r.schema.Fields {
-			// Busca apenas em campos de texto/string
-			if field.Type == "string" || field.Type == "text" {
-				whereClause = append(whereClause, fmt.Sprintf("%s LIKE ?", field.Name))
-				args = append(args, searchLike)
-			}
+	var found int
+	err := r.db.QueryRow(query+" LIMIT 1", args...).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// duplicateError converte o erro 1062 do MySQL ("Duplicate entry 'x' for key 'tabela.indice'")
+// em *DuplicateError; índices UNIQUE de coluna têm o nome da coluna. Outros erros passam direto.
+func (r *DynamicRepository) duplicateError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1062 {
+		return err
+	}
+
+	_, index, _ := strings.Cut(mysqlErr.Message, " for key '")
+	index = strings.TrimSuffix(index, "'")
+	if i := strings.LastIndex(index, "."); i >= 0 { // MySQL 8 prefixa com o nome da tabela
+		index = index[i+1:]
+	}
+
+	if index == "PRIMARY" {
+		if keys := r.schema.PrimaryKeyFields(); len(keys) == 1 {
+			return &DuplicateError{Field: keys[0].Name}
 		}
-		if len(whereClause) > 0 {
-			clause := fmt.Sprintf(" WHERE %s", strings.Join(whereClause, " OR "))
-			query.WriteString(clause)
-			countQuery.WriteString(clause)
+		return &DuplicateError{}
+	}
+	for _, field := range r.schema.Fields {
+		if strings.EqualFold(field.Name, index) {
+			return &DuplicateError{Field: field.Name}
 		}
 	}
+	return &DuplicateError{}
+}
+
+// keyWhere monta a condição WHERE da chave primária (ex.: "pedido_id = ? AND produto_id = ?")
+func (r *DynamicRepository) keyWhere(key Key) (string, error) {
+	fields := r.schema.PrimaryKeyFields()
+	if len(fields) == 0 {
+		return "", fmt.Errorf("nenhuma chave primária definida no schema")
+	}
+	if len(key) != len(fields) {
+		return "", fmt.Errorf("chave primária deve ter %d valor(es), recebeu %d", len(fields), len(key))
+	}
+
+	conditions := make([]string, len(fields))
+	for i, field := range fields {
+		conditions[i] = fmt.Sprintf("%s = ?", field.Name)
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+// Tamanho mínimo das palavras no índice FULLTEXT do InnoDB (innodb_ft_min_token_size);
+// palavras menores não são encontradas por MATCH
+const minFullTextWord = 3
+
+// fullTextStopwords são as palavras da lista padrão do InnoDB (INNODB_FT_DEFAULT_STOPWORD)
+// com o tamanho mínimo: não são indexadas, então exigi-las na busca não encontraria nada
+var fullTextStopwords = map[string]bool{
+	"about": true, "are": true, "com": true, "for": true, "from": true, "how": true,
+	"that": true, "the": true, "this": true, "was": true, "what": true, "when": true,
+	"where": true, "who": true, "will": true, "with": true, "und": true, "www": true,
+}
+
+// ListOptions descreve a página da lista pedida a FindAll
+type ListOptions struct {
+	Page   int    // A partir de 1
+	Limit  int    // Registros por página
+	Search string // Texto buscado nos campos string/text ("" lista tudo)
+	Sort   string // Campo da ordenação ("" ordena pela chave primária)
+	Desc   bool   // Ordem decrescente
+}
+
+// FindAll busca uma página de registros, com busca e ordenação, e o total de registros
+// que atendem à busca. A chave primária desempata a ordenação, para a paginação ser estável.
+func (r *DynamicRepository) FindAll(opts ListOptions) ([]map[string]interface{}, int, error) {
+	where, args := r.searchWhere(opts.Search)
+	query := "SELECT * FROM " + r.schema.TableName
+	if where != "" {
+		query += " WHERE " + where
+	}
+
+	// Sem ordenação escolhida, a busca full-text traz os mais relevantes primeiro
+	queryArgs := args
+	if match, terms := r.fullTextMatch(opts.Search); match != "" && opts.Sort == "" {
+		order := []string{match + " DESC"}
+		for _, field := range r.schema.PrimaryKeyFields() {
+			order = append(order, field.Name)
+		}
+		query += " ORDER BY " + strings.Join(order, ", ")
+		queryArgs = append(append([]interface{}{}, args...), terms)
+	} else {
+		orderBy, err := r.orderBy(opts.Sort, opts.Desc)
+		if err != nil {
+			return nil, 0, err
+		}
+		query += orderBy
+	}
 
 	// Contagem total (para paginação)
-	var totalRecords int
-	err := r.db.QueryRow(countQuery.String(), args...).Scan(&totalRecords)
+	totalRecords, err := r.countRecords(where, args)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Paginação
-	offset := (page - 1) * limit
-	query.WriteString(fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset))
+	offset := (opts.Page - 1) * opts.Limit
+	query += fmt.Sprintf(" LIMIT %d OFFSET %d", opts.Limit, offset)
 
 	// Executa a query principal
-	rows, err := r.db.Query(query.String(), args...)
+	results, err := r.queryRows(query, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
+	return results, totalRecords, nil
+}
+
+// searchWhere monta a condição da busca (sem o WHERE), ou "" se não há busca. Com campos
+// "searchable", usa o índice FULLTEXT (MATCH ... AGAINST); sem eles, ou se nenhuma palavra
+// da busca tem o tamanho mínimo indexado, busca o trecho com LIKE nos campos searchable
+// (ou em todos os string/text).
+func (r *DynamicRepository) searchWhere(search string) (string, []interface{}) {
+	if search == "" {
+		return "", nil
+	}
+	if match, query := r.fullTextMatch(search); match != "" {
+		return match, []interface{}{query}
+	}
+
+	fields := r.schema.SearchableFields()
+	if len(fields) == 0 {
+		fields = r.schema.Fields
+	}
+	whereClause := []string{}
+	args := []interface{}{}
+	searchLike := "%" + escapeLike(search) + "%"
+	for _, field := range fields {
+		// Busca apenas em campos de texto/string
+		if field.Type == "string" || field.Type == "text" {
+			whereClause = append(whereClause, fmt.Sprintf("%s LIKE ?", field.Name))
+			args = append(args, searchLike)
+		}
+	}
+	return strings.Join(whereClause, " OR "), args
+}
+
+// fullTextMatch monta o MATCH ... AGAINST sobre o índice de busca e o texto buscado em
+// modo booleano: todas as palavras precisam aparecer, como palavra ou prefixo (ana → ana*,
+// que também encontra "Anabela"). Retorna "" se não há campos searchable ou palavras
+// indexáveis (com o tamanho mínimo e fora das stopwords).
+func (r *DynamicRepository) fullTextMatch(search string) (string, string) {
+	columns := []string{}
+	for _, field := range r.schema.SearchableFields() {
+		columns = append(columns, field.Name)
+	}
+	if len(columns) == 0 {
+		return "", ""
+	}
+
+	// Só letras e dígitos: os operadores do modo booleano (+ - * " etc.) viram separadores
+	words := strings.FieldsFunc(search, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	terms := []string{}
+	for _, word := range words {
+		if utf8.RuneCountInString(word) >= minFullTextWord && !fullTextStopwords[strings.ToLower(word)] {
+			terms = append(terms, "+"+word+"*")
+		}
+	}
+	if len(terms) == 0 {
+		return "", ""
+	}
+	return fmt.Sprintf("MATCH (%s) AGAINST (? IN BOOLEAN MODE)", strings.Join(columns, ", ")), strings.Join(terms, " ")
+}
+
+// escapeLike escapa os curingas do LIKE (% e _) e a barra usada para escapá-los, para o
+// texto buscado ser procurado literalmente
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// countRecords conta os registros que atendem à condição where ("" conta todos)
+func (r *DynamicRepository) countRecords(where string, args []interface{}) (int, error) {
+	query := "SELECT COUNT(*) FROM " + r.schema.TableName
+	if where != "" {
+		query += " WHERE " + where
+	}
+	var total int
+	err := r.db.QueryRow(query, args...).Scan(&total)
+	return total, err
+}
+
+// queryRows executa a consulta e converte as linhas com scanRowToMap
+func (r *DynamicRepository) queryRows(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanRows(rows, r.schema)
+}
+
+// scanRows converte todas as linhas com scanRowToMap e fecha rows
+func scanRows(rows *sql.Rows, schema *Schema) ([]map[string]interface{}, error) {
 	defer rows.Close()
 
 	results := []map[string]interface{}{}
 	for rows.Next() {
-		rowMap, err := scanRowToMap(rows)
+		rowMap, err := scanRowToMap(rows, schema)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		results = append(results, rowMap)
 	}
+	return results, rows.Err()
+}
+
+// orderBy monta o ORDER BY pelo campo informado (que precisa existir no schema e ser
+// ordenável) seguido das colunas da chave primária
+func (r *DynamicRepository) orderBy(sort string, desc bool) (string, error) {
+	columns := []string{}
+	if sort != "" {
+		field, ok := r.schema.FieldByName(sort)
+		if !ok || !field.IsSortable() {
+			return "", fmt.Errorf("não é possível ordenar por %q", sort)
+		}
+		direction := "ASC"
+		if desc {
+			direction = "DESC"
+		}
+		columns = append(columns, field.Name+" "+direction)
+	}
+	for _, field := range r.schema.PrimaryKeyFields() {
+		if field.Name != sort {
+			columns = append(columns, field.Name)
+		}
+	}
+	return " ORDER BY " + strings.Join(columns, ", "), nil
+}
 
-	return results, totalRecords, nil
+// ForEach percorre todos os registros da tabela em ordem de chave primária, sem carregar
+// tudo na memória (ex.: exportação). Para no primeiro erro retornado por fn.
+func (r *DynamicRepository) ForEach(fn func(row map[string]interface{}) error) error {
+	order := []string{}
+	for _, field := range r.schema.PrimaryKeyFields() {
+		order = append(order, field.Name)
+	}
+	query := "SELECT * FROM " + r.schema.TableName
+	if len(order) > 0 {
+		query += " ORDER BY " + strings.Join(order, ", ")
+	}
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row, err := scanRowToMap(rows, r.schema)
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
 
-// scanRowToMap é um helper para scanear uma linha de *sql.Rows para um map
-func scanRowToMap(rows *sql.Rows) (map[string]interface{}, error) {
+// scanRowToMap é um helper para scanear uma linha de *sql.Rows para um map.
+// Colunas definidas no schema são convertidas para o tipo Go do campo (ver convertColumnValue).
+func scanRowToMap(rows *sql.Rows, schema *Schema) (map[string]interface{}, error) {
 	cols, err := rows.Columns()
 	if err != nil {
 		return nil, err
@@ -223,15 +603,57 @@ func scanRowToMap(rows *sql.Rows) (map[string]interface{}, error) {
 		return nil, err
 	}
 
+	fields := make(map[string]Field, len(schema.Fields))
+	for _, field := range schema.Fields {
+		fields[field.Name] = field
+	}
+
 	rowMap := make(map[string]interface{})
 	for i, col := range cols {
 		if vals[i] == nil {
 			rowMap[col] = nil
+			continue
+		}
+		if field, ok := fields[col]; ok {
+			rowMap[col] = convertColumnValue(field, vals[i])
 		} else {
-			// Tenta manter o tipo, mas string é o mais seguro
+			// Colunas fora do schema (ex.: versão) ficam como string
 			rowMap[col] = string(vals[i])
 		}
 	}
 
 	return rowMap, nil
 }
+
+// convertColumnValue converte o valor bruto de uma coluna para o tipo Go do campo:
+// int64 (int, bigint), float64 (float), bool, time.Time (date, datetime),
+// json.RawMessage (json) e string para os demais (decimal fica string para não perder precisão).
+// Se a conversão falhar, mantém o valor como string.
+func convertColumnValue(field Field, raw []byte) interface{} {
+	value := string(raw)
+
+	switch field.BaseType() {
+	case "int", "bigint":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "bool":
+		return value != "0" && value != ""
+	case "date", "datetime":
+		// Com parseTime=true o driver entrega RFC3339; o texto puro do MySQL fica de fallback
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t
+			}
+		}
+	case "json":
+		if json.Valid(raw) {
+			return json.RawMessage(value)
+		}
+	}
+	return value
+}
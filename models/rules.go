@@ -0,0 +1,454 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule é uma regra de validação entre campos, declarada em "rules" no schema. check é uma
+// expressão que precisa ser verdadeira (ex.: "data_fim >= data_inicio"); when, se informada,
+// restringe a regra aos registros em que ela é verdadeira (ex.: "tipo_pessoa == 'PJ'").
+// A mesma linguagem é avaliada no servidor (validators.CheckRules) e no navegador (main.js).
+type Rule struct {
+	When    string   `json:"when"`    // Condição para a regra valer (opcional)
+	Check   string   `json:"check"`   // Expressão que o registro precisa satisfazer
+	Fields  []string `json:"fields"`  // Campos que recebem a mensagem (vazio: erro geral do formulário)
+	Message string   `json:"message"` // Erro exibido quando a regra falha
+}
+
+// RuleFunctions são as funções aceitas nas expressões das regras
+var RuleFunctions = []string{"filled", "empty"}
+
+// Números em texto (ex.: decimal) são comparados como números
+var ruleNumberRegex = regexp.MustCompile(`^[+-]?\d+(\.\d+)?$`)
+
+// References lista os campos citados em when e check, sem repetição
+func (r Rule) References() ([]string, error) {
+	refs := []string{}
+	for _, src := range []string{r.When, r.Check} {
+		if src == "" {
+			continue
+		}
+		expr, err := ParseRuleExpr(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range expr.Idents() {
+			if !slices.Contains(refs, name) {
+				refs = append(refs, name)
+			}
+		}
+	}
+	return refs, nil
+}
+
+// Holds indica se o registro satisfaz a regra. values são os valores já normalizados por
+// RuleValue; a regra vale quando when é vazia ou verdadeira.
+func (r Rule) Holds(values map[string]interface{}) (bool, error) {
+	if r.When != "" {
+		when, err := ParseRuleExpr(r.When)
+		if err != nil {
+			return false, err
+		}
+		if !when.Eval(values) {
+			return true, nil
+		}
+	}
+	check, err := ParseRuleExpr(r.Check)
+	if err != nil {
+		return false, err
+	}
+	return check.Eval(values), nil
+}
+
+// RuleValue normaliza o valor limpo de um campo (o que vai para o banco ou vem dele) para
+// a representação das regras, a mesma que o navegador lê do formulário: nil para vazio,
+// bool, float64 para números e texto para o resto (datas AAAA-MM-DD, data e hora
+// AAAA-MM-DDTHH:MM, hora HH:MM).
+func RuleValue(field Field, value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case bool:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case time.Time:
+		if field.BaseType() == "datetime" {
+			return v.Format("2006-01-02T15:04")
+		}
+		return v.Format("2006-01-02")
+	}
+
+	text := fmt.Sprint(value)
+	if text == "" {
+		return nil
+	}
+	if field.BaseType() == "time" && len(text) > 5 {
+		return text[:5]
+	}
+	return text
+}
+
+// RuleExpr é uma expressão de regra já interpretada
+type RuleExpr struct {
+	root   ruleNode
+	idents []string
+}
+
+// Idents lista os campos citados na expressão, na ordem em que aparecem
+func (e *RuleExpr) Idents() []string {
+	return e.idents
+}
+
+// Eval avalia a expressão sobre os valores normalizados (ver RuleValue). Campos ausentes
+// de values contam como vazios.
+func (e *RuleExpr) Eval(values map[string]interface{}) bool {
+	return ruleTruthy(e.root.eval(values))
+}
+
+// ParseRuleExpr interpreta uma expressão de regra. A linguagem tem campos (pelo nome),
+// textos entre aspas simples ou duplas, números, true, false e null; os operadores
+// == != < <= > >=, && || ! e parênteses; e as funções filled(campo) e empty(campo).
+func ParseRuleExpr(src string) (*RuleExpr, error) {
+	tokens, err := tokenizeRule(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &ruleParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != ruleEOF {
+		return nil, fmt.Errorf("%q inesperado na posição %d", tok.text, tok.pos+1)
+	}
+	return &RuleExpr{root: root, idents: p.idents}, nil
+}
+
+// --- Tokens ---
+
+type ruleTokenKind int
+
+const (
+	ruleEOF ruleTokenKind = iota
+	ruleIdent
+	ruleNumber
+	ruleString
+	ruleOp
+)
+
+type ruleToken struct {
+	kind ruleTokenKind
+	text string
+	pos  int
+}
+
+// Operadores, os de dois caracteres primeiro
+var ruleOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")"}
+
+func tokenizeRule(src string) ([]ruleToken, error) {
+	tokens := []ruleToken{}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("texto sem aspas de fechamento na posição %d", i+1)
+			}
+			tokens = append(tokens, ruleToken{kind: ruleString, text: src[i+1 : i+1+end], pos: i})
+			i += end + 2
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, ruleToken{kind: ruleNumber, text: src[start:i], pos: start})
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] >= 'a' && src[i] <= 'z' || src[i] >= 'A' && src[i] <= 'Z' || src[i] >= '0' && src[i] <= '9') {
+				i++
+			}
+			tokens = append(tokens, ruleToken{kind: ruleIdent, text: src[start:i], pos: start})
+		default:
+			op := ""
+			for _, candidate := range ruleOperators {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("caractere %q inesperado na posição %d", c, i+1)
+			}
+			tokens = append(tokens, ruleToken{kind: ruleOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, ruleToken{kind: ruleEOF, text: "fim da expressão", pos: len(src)}), nil
+}
+
+// --- Parser (descida recursiva: || < && < ! < comparação < termo) ---
+
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+	idents []string
+}
+
+func (p *ruleParser) peek() ruleToken {
+	return p.tokens[p.pos]
+}
+
+func (p *ruleParser) next() ruleToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != ruleEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *ruleParser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == ruleOp && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.accept("||") {
+		var right ruleNode
+		if right, err = p.parseAnd(); err == nil {
+			left = ruleBinary{op: "||", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseNot()
+	for err == nil && p.accept("&&") {
+		var right ruleNode
+		if right, err = p.parseNot(); err == nil {
+			left = ruleBinary{op: "&&", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *ruleParser) parseNot() (ruleNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseNot()
+		return ruleNot{operand: operand}, err
+	}
+	return p.parseComparison()
+}
+
+func (p *ruleParser) parseComparison() (ruleNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parseTerm()
+			return ruleBinary{op: op, left: left, right: right}, err
+		}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseTerm() (ruleNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case ruleString:
+		return ruleLiteral{value: tok.text}, nil
+	case ruleNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("número inválido %q na posição %d", tok.text, tok.pos+1)
+		}
+		return ruleLiteral{value: n}, nil
+	case ruleIdent:
+		switch tok.text {
+		case "true":
+			return ruleLiteral{value: true}, nil
+		case "false":
+			return ruleLiteral{value: false}, nil
+		case "null":
+			return ruleLiteral{value: nil}, nil
+		}
+		if p.accept("(") {
+			return p.parseCall(tok)
+		}
+		if !slices.Contains(p.idents, tok.text) {
+			p.idents = append(p.idents, tok.text)
+		}
+		return ruleIdentNode{name: tok.text}, nil
+	case ruleOp:
+		if tok.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, fmt.Errorf("falta \")\" na posição %d", p.peek().pos+1)
+			}
+			return inner, nil
+		}
+	}
+	return nil, fmt.Errorf("esperava um campo, valor ou \"(\" na posição %d, encontrou %q", tok.pos+1, tok.text)
+}
+
+func (p *ruleParser) parseCall(name ruleToken) (ruleNode, error) {
+	if !slices.Contains(RuleFunctions, name.text) {
+		return nil, fmt.Errorf("função desconhecida %q na posição %d (funções: %s)", name.text, name.pos+1, strings.Join(RuleFunctions, ", "))
+	}
+	arg, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(")") {
+		return nil, fmt.Errorf("%s recebe um argumento; falta \")\" na posição %d", name.text, p.peek().pos+1)
+	}
+	return ruleCall{name: name.text, arg: arg}, nil
+}
+
+// --- Avaliação ---
+
+type ruleNode interface {
+	eval(values map[string]interface{}) interface{}
+}
+
+type ruleLiteral struct{ value interface{} }
+
+type ruleIdentNode struct{ name string }
+
+type ruleNot struct{ operand ruleNode }
+
+type ruleCall struct {
+	name string
+	arg  ruleNode
+}
+
+type ruleBinary struct {
+	op          string
+	left, right ruleNode
+}
+
+func (n ruleLiteral) eval(map[string]interface{}) interface{} { return n.value }
+
+func (n ruleIdentNode) eval(values map[string]interface{}) interface{} { return values[n.name] }
+
+func (n ruleNot) eval(values map[string]interface{}) interface{} {
+	return !ruleTruthy(n.operand.eval(values))
+}
+
+func (n ruleCall) eval(values map[string]interface{}) interface{} {
+	value := n.arg.eval(values)
+	filled := value != nil && value != ""
+	if n.name == "empty" {
+		return !filled
+	}
+	return filled
+}
+
+func (n ruleBinary) eval(values map[string]interface{}) interface{} {
+	switch n.op {
+	case "&&":
+		return ruleTruthy(n.left.eval(values)) && ruleTruthy(n.right.eval(values))
+	case "||":
+		return ruleTruthy(n.left.eval(values)) || ruleTruthy(n.right.eval(values))
+	}
+
+	a, b := n.left.eval(values), n.right.eval(values)
+	switch n.op {
+	case "==":
+		return ruleEqual(a, b)
+	case "!=":
+		return !ruleEqual(a, b)
+	}
+
+	// Comparação de ordem com um lado vazio não se aplica (use filled() para exigir o valor)
+	if a == nil || b == nil {
+		return true
+	}
+	cmp := ruleCompare(a, b)
+	switch n.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// ruleTruthy: vazio, false e "" são falsos; qualquer outro valor é verdadeiro
+func ruleTruthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+func ruleEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if x, ok := ruleToNumber(a); ok {
+		if y, ok := ruleToNumber(b); ok {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// ruleCompare compara como números quando os dois lados são números, senão como texto
+func ruleCompare(a, b interface{}) int {
+	if x, ok := ruleToNumber(a); ok {
+		if y, ok := ruleToNumber(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func ruleToNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		if ruleNumberRegex.MatchString(v) {
+			n, err := strconv.ParseFloat(v, 64)
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,236 @@
+package models
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// ruleCase é um caso de testdata/rule_cases.json, compartilhado com o avaliador do
+// navegador (static/js/rules.js)
+type ruleCase struct {
+	Expr   string                 `json:"expr"`
+	Values map[string]interface{} `json:"values"`
+	Want   bool                   `json:"want"`
+	Error  bool                   `json:"error"`
+}
+
+func loadRuleCases(t *testing.T) []ruleCase {
+	t.Helper()
+	data, err := os.ReadFile("testdata/rule_cases.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cases []ruleCase
+	if err := json.Unmarshal(data, &cases); err != nil {
+		t.Fatal(err)
+	}
+	return cases
+}
+
+func TestTokenizeRule(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string // "tipo:texto"
+	}{
+		{"a == 'x y'", []string{"ident:a", "op:==", "string:x y"}},
+		{`b!="PJ"`, []string{"ident:b", "op:!=", "string:PJ"}},
+		{"valor>=10.5&&x", []string{"ident:valor", "op:>=", "number:10.5", "op:&&", "ident:x"}},
+		{"!(a||b)", []string{"op:!", "op:(", "ident:a", "op:||", "ident:b", "op:)"}},
+		{"c <= d_2 < e > f", []string{"ident:c", "op:<=", "ident:d_2", "op:<", "ident:e", "op:>", "ident:f"}},
+		{"filled(cnpj)", []string{"ident:filled", "op:(", "ident:cnpj", "op:)"}},
+		{" \t\n", []string{}},
+	}
+	kinds := map[ruleTokenKind]string{ruleIdent: "ident", ruleNumber: "number", ruleString: "string", ruleOp: "op"}
+	for _, tt := range tests {
+		tokens, err := tokenizeRule(tt.src)
+		if err != nil {
+			t.Errorf("tokenizeRule(%q): %v", tt.src, err)
+			continue
+		}
+		if last := tokens[len(tokens)-1]; last.kind != ruleEOF || last.pos != len(tt.src) {
+			t.Errorf("tokenizeRule(%q): último token %+v, esperava o fim na posição %d", tt.src, last, len(tt.src))
+		}
+		got := []string{}
+		for _, tok := range tokens[:len(tokens)-1] {
+			got = append(got, kinds[tok.kind]+":"+tok.text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenizeRule(%q) = %v, esperava %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeRuleErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"a == 'x", "texto sem aspas de fechamento na posição 6"},
+		{"a = 1", "caractere '=' inesperado na posição 3"},
+		{"a & b", "caractere '&' inesperado na posição 3"},
+		{"a @ b", "caractere '@' inesperado na posição 3"},
+	}
+	for _, tt := range tests {
+		_, err := tokenizeRule(tt.src)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("tokenizeRule(%q) erro = %v, esperava %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestParseRuleExpr(t *testing.T) {
+	tests := []struct {
+		src    string
+		idents []string
+	}{
+		{"tipo == 'PJ'", []string{"tipo"}},
+		{"fim >= inicio && fim != null", []string{"fim", "inicio"}},
+		{"filled(telefone) || empty(email)", []string{"telefone", "email"}},
+		{"true", nil},
+		{"!(a && (b || c))", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		expr, err := ParseRuleExpr(tt.src)
+		if err != nil {
+			t.Errorf("ParseRuleExpr(%q): %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(expr.Idents(), tt.idents) {
+			t.Errorf("ParseRuleExpr(%q).Idents() = %v, esperava %v", tt.src, expr.Idents(), tt.idents)
+		}
+	}
+}
+
+func TestParseRuleExprErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"", `esperava um campo, valor ou "(" na posição 1, encontrou "fim da expressão"`},
+		{"a ==", `esperava um campo, valor ou "(" na posição 5, encontrou "fim da expressão"`},
+		{"(a", `falta ")" na posição 3`},
+		{"a)", `")" inesperado na posição 2`},
+		{"a < 1 2", `"2" inesperado na posição 7`},
+		{"a == 1 == 2", `"==" inesperado na posição 8`},
+		{"1.2.3 == a", `número inválido "1.2.3" na posição 1`},
+		{"len(a)", `função desconhecida "len" na posição 1 (funções: filled, empty)`},
+		{"filled(a", `filled recebe um argumento; falta ")" na posição 9`},
+		{"a < -1", "caractere '-' inesperado na posição 5"}, // Não há números negativos
+	}
+	for _, tt := range tests {
+		_, err := ParseRuleExpr(tt.src)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("ParseRuleExpr(%q) erro = %v, esperava %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+// Os casos compartilhados com o navegador
+func TestRuleExprEval(t *testing.T) {
+	for _, c := range loadRuleCases(t) {
+		expr, err := ParseRuleExpr(c.Expr)
+		if c.Error {
+			if err == nil {
+				t.Errorf("ParseRuleExpr(%q): esperava erro", c.Expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRuleExpr(%q): %v", c.Expr, err)
+			continue
+		}
+		if got := expr.Eval(c.Values); got != c.Want {
+			t.Errorf("%q com %v = %v, esperava %v", c.Expr, c.Values, got, c.Want)
+		}
+	}
+}
+
+// O avaliador do navegador precisa concordar com o do Go nos mesmos casos
+func TestRuleCasesAgreeWithJavaScript(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node não encontrado; o avaliador JavaScript não foi conferido")
+	}
+	out, err := exec.Command(node, "testdata/rules_agree.js", "../static/js/rules.js", "testdata/rule_cases.json").CombinedOutput()
+	if err != nil {
+		t.Errorf("static/js/rules.js discorda dos casos de testdata/rule_cases.json (%v):\n%s", err, out)
+	}
+}
+
+func TestRuleEvalNilOrdering(t *testing.T) {
+	// Comparações de ordem com um lado vazio não se aplicam (são verdadeiras)...
+	for _, op := range []string{"<", "<=", ">", ">="} {
+		expr, err := ParseRuleExpr("a " + op + " b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, values := range []map[string]interface{}{{}, {"a": 1.0}, {"b": "x"}, {"a": nil, "b": nil}} {
+			if !expr.Eval(values) {
+				t.Errorf("a %s b com %v = false, esperava true", op, values)
+			}
+		}
+	}
+	// ...mas a igualdade distingue vazio de preenchido
+	eq, _ := ParseRuleExpr("a == b")
+	if eq.Eval(map[string]interface{}{"a": 1.0}) {
+		t.Error("1 == null deveria ser falso")
+	}
+	if !eq.Eval(map[string]interface{}{}) {
+		t.Error("null == null deveria ser verdadeiro")
+	}
+}
+
+func TestRuleHolds(t *testing.T) {
+	rule := Rule{When: "tipo == 'PJ'", Check: "filled(cnpj)"}
+	tests := []struct {
+		values map[string]interface{}
+		want   bool
+	}{
+		{map[string]interface{}{"tipo": "PF"}, true}, // when falso: a regra não vale
+		{map[string]interface{}{"tipo": "PJ"}, false},
+		{map[string]interface{}{"tipo": "PJ", "cnpj": "123"}, true},
+	}
+	for _, tt := range tests {
+		got, err := rule.Holds(tt.values)
+		if err != nil || got != tt.want {
+			t.Errorf("Holds(%v) = %v, %v; esperava %v", tt.values, got, err, tt.want)
+		}
+	}
+
+	refs, err := Rule{When: "a == b", Check: "filled(b) || c"}.References()
+	if err != nil || !reflect.DeepEqual(refs, []string{"a", "b", "c"}) {
+		t.Errorf("References() = %v, %v", refs, err)
+	}
+	if _, err := (Rule{Check: "a =="}).Holds(nil); err == nil {
+		t.Error("Holds com expressão inválida deveria falhar")
+	}
+}
+
+func TestRuleValue(t *testing.T) {
+	day := time.Date(2024, 5, 10, 14, 30, 59, 0, time.UTC)
+	tests := []struct {
+		field Field
+		value interface{}
+		want  interface{}
+	}{
+		{Field{Type: "string"}, nil, nil},
+		{Field{Type: "string"}, "", nil},
+		{Field{Type: "string"}, "abc", "abc"},
+		{Field{Type: "bool"}, false, false},
+		{Field{Type: "int"}, 7, 7.0},
+		{Field{Type: "bigint"}, int64(9), 9.0},
+		{Field{Type: "float"}, 2.5, 2.5},
+		{Field{Type: "decimal(10,2)"}, "10.50", "10.50"},
+		{Field{Type: "date"}, day, "2024-05-10"},
+		{Field{Type: "datetime"}, day, "2024-05-10T14:30"},
+		{Field{Type: "time"}, "14:30:59", "14:30"},
+		{Field{Type: "time"}, "14:30", "14:30"},
+	}
+	for _, tt := range tests {
+		if got := RuleValue(tt.field, tt.value); got != tt.want {
+			t.Errorf("RuleValue(%s, %#v) = %#v, esperava %#v", tt.field.Type, tt.value, got, tt.want)
+		}
+	}
+}
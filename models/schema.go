@@ -1,50 +1,196 @@
 package models
 
 import (
-	"encoding/json"
-	"os"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 )
 
 // Schema representa a estrutura completa do JSON
 type Schema struct {
-	TableName string  `json:"table_name"`
-	Fields    []Field `json:"fields"`
+	TableName    string  `json:"table_name"`
+	Fields       []Field `json:"fields"`
+	VersionField string  `json:"version_field"` // Coluna de versão para lock otimista (opcional)
+	Theme        Theme   `json:"theme"`         // Título, logo e cores da página (opcional)
+	Rules        []Rule  `json:"rules"`         // Regras de validação entre campos (opcional)
 }
 
 // Field representa um campo no schema
 type Field struct {
-	Name       string     `json:"name"`
-	Type       string     `json:"type"` // int, string, date, text
-	PrimaryKey bool       `json:"primary_key"`
-	Required   bool       `json:"required"`
-	Validation Validation `json:"validation"`
-	Mask       string     `json:"mask"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"` // Ver FieldTypes (ex.: int, string, date, bool, decimal(12,4))
+	PrimaryKey  bool       `json:"primary_key"`
+	Required    bool       `json:"required"`
+	Validation  Validation `json:"validation"`
+	Mask        string     `json:"mask"`
+	Enum        []string   `json:"enum"`        // Valores permitidos (renderizado como <select>)
+	Widget      string     `json:"widget"`      // Sobrescreve o controle do formulário (ver InputWidget)
+	Label       string     `json:"label"`       // Rótulo exibido (padrão: Name)
+	Placeholder string     `json:"placeholder"` // Texto de exemplo no input
+	Help        string     `json:"help"`        // Texto de ajuda abaixo do input
+	MaxSizeMB   int        `json:"max_size_mb"` // Tamanho máximo de upload (file/image)
+	Accept      []string   `json:"accept"`      // Tipos MIME aceitos no upload (ex.: "image/*", "application/pdf")
+	Length      int        `json:"length"`      // Tamanho máximo de campos string (VARCHAR(length), padrão 255)
+	Unique      bool       `json:"unique"`      // Cria índice UNIQUE na coluna
+	Searchable  bool       `json:"searchable"`  // Entra na busca full-text (índice FULLTEXT; só string/text)
 }
 
 // Validation define as regras de validação
 type Validation struct {
-	Type       string      `json:"type"` // cpf, cnpj, email, telefone, cep, rg
+	Type       string      `json:"type"` // Ver ValidationTypes
 	RegexRules []RegexRule `json:"regex_rules"`
 }
 
+// ValidationTypes lista os valores aceitos em validation.type
+var ValidationTypes = []string{"cpf", "cnpj", "email", "telefone", "cep"}
+
+// MaskSeparators são os caracteres fixos das máscaras removidos do valor antes de salvar
+const MaskSeparators = ".-()/ _"
+
 // RegexRule define uma regra de regex customizada
 type RegexRule struct {
 	Pattern string `json:"pattern"`
 	Message string `json:"message"`
+
+	re *regexp.Regexp // Compilada uma vez, ao carregar o schema (ver compileRegexRules)
+}
+
+// Match indica se o valor atende à regra. Regras que não vieram de um arquivo de schema
+// (sem a regex compilada) compilam o padrão a cada chamada; um padrão inválido não aceita nada.
+func (r RegexRule) Match(value string) bool {
+	re := r.re
+	if re == nil {
+		var err error
+		if re, err = regexp.Compile(r.Pattern); err != nil {
+			return false
+		}
+	}
+	return re.MatchString(value)
+}
+
+// compileRegexRules compila as regex_rules dos campos para a validação reutilizá-las
+func (s *Schema) compileRegexRules() error {
+	for _, field := range s.Fields {
+		rules := field.Validation.RegexRules
+		for i := range rules {
+			re, err := regexp.Compile(rules[i].Pattern)
+			if err != nil {
+				return fmt.Errorf("campo %q: regex inválida: %w", field.Name, err)
+			}
+			rules[i].re = re
+		}
+	}
+	return nil
 }
 
-// LoadSchema lê e parseia o arquivo JSON do schema
+// LoadSchema lê, valida e parseia o arquivo do schema. O formato é escolhido pela
+// extensão: .yaml/.yml, .toml ou JSON (qualquer outra), todos com as mesmas regras.
+// Problemas no conteúdo são retornados como *SchemaError, com linha e coluna de cada um.
 func LoadSchema(path string) (*Schema, error) {
+	root, err := readSchemaNode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := decodeSchemaNode(root)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao parsear schema: %w", err)
+	}
+	return schema, nil
+}
+
+// ConvertSchemaFile converte o schema de um formato para outro (escolhidos pelas
+// extensões dos arquivos), mantendo a ordem das chaves. O schema de origem é validado antes.
+func ConvertSchemaFile(srcPath, dstPath string) error {
+	root, err := readSchemaNode(srcPath)
+	if err != nil {
+		return err
+	}
+
+	return writeSchemaNode(dstPath, root)
+}
+
+// WriteSchemaFile grava o schema no formato da extensão do arquivo, omitindo as
+// propriedades com valor padrão (vazias, zero ou false)
+func WriteSchemaFile(path string, schema *Schema) error {
+	return writeSchemaNode(path, nodeFromValue(reflect.ValueOf(*schema)))
+}
+
+// writeSchemaNode grava a árvore no formato da extensão do arquivo
+func writeSchemaNode(path string, root *schemaNode) error {
+	var out []byte
+	var err error
+	switch schemaFormat(path) {
+	case "yaml":
+		out, err = encodeYAMLNode(root)
+	case "toml":
+		out, err = encodeTOMLNode(root)
+	default:
+		out = encodeJSONNode(root)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao gerar schema: %w", err)
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// schemaFormat identifica o formato do arquivo de schema pela extensão
+func schemaFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	}
+	return "json"
+}
+
+// readSchemaNode lê o arquivo no formato da extensão e valida a árvore resultante
+func readSchemaNode(path string) (*schemaNode, error) {
 	file, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao ler arquivo schema: %w", err)
 	}
 
-	var schema Schema
-	if err := json.Unmarshal(file, &schema); err != nil {
-		return nil, fmt.Errorf("erro ao parsear JSON schema: %w", err)
+	var root *schemaNode
+	var issues []SchemaIssue
+	switch schemaFormat(path) {
+	case "yaml":
+		root, issues = parseYAMLNode(file)
+	case "toml":
+		root, issues = parseTOMLNode(file)
+	default:
+		root, issues = parseJSONNode(file)
+	}
+	if root != nil {
+		issues = lintSchemaNode(root)
+	}
+	if len(issues) > 0 {
+		return nil, &SchemaError{Path: path, Issues: issues}
+	}
+	return root, nil
+}
+
+// SearchableFields retorna os campos marcados com "searchable", na ordem do schema
+func (s *Schema) SearchableFields() []Field {
+	fields := []Field{}
+	for _, field := range s.Fields {
+		if field.Searchable {
+			fields = append(fields, field)
+		}
 	}
+	return fields
+}
 
-	return &schema, nil
+// FieldByName retorna o campo com o nome informado
+func (s *Schema) FieldByName(name string) (Field, bool) {
+	for _, field := range s.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return Field{}, false
 }
@@ -0,0 +1,506 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tamanho máximo de um identificador no MySQL
+const maxIdentifierLength = 64
+
+// Maior VARCHAR possível com utf8mb4 (limite de 65.535 bytes por linha / 4 bytes por caractere)
+const maxVarcharLength = 16383
+
+// Nomes de tabela e coluna entram direto no SQL, então só aceitamos identificadores simples
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Tipos que o MySQL não consegue usar como chave primária (ou que não fazem sentido como chave)
+var nonKeyTypes = []string{"text", "json", "file", "image"}
+
+// sqlReservedWords são as palavras reservadas do MySQL 8 que quebrariam as queries
+// geradas se usadas como nome de tabela ou coluna
+var sqlReservedWords = toSet(strings.Fields(`
+	ACCESSIBLE ADD ALL ALTER ANALYZE AND AS ASC ASENSITIVE BEFORE BETWEEN BIGINT BINARY BLOB
+	BOTH BY CALL CASCADE CASE CHANGE CHAR CHARACTER CHECK COLLATE COLUMN CONDITION CONSTRAINT
+	CONTINUE CONVERT CREATE CROSS CUBE CUME_DIST CURRENT_DATE CURRENT_TIME CURRENT_TIMESTAMP
+	CURRENT_USER CURSOR DATABASE DATABASES DAY_HOUR DAY_MICROSECOND DAY_MINUTE DAY_SECOND DEC
+	DECIMAL DECLARE DEFAULT DELAYED DELETE DENSE_RANK DESC DESCRIBE DETERMINISTIC DISTINCT
+	DISTINCTROW DIV DOUBLE DROP DUAL EACH ELSE ELSEIF EMPTY ENCLOSED ESCAPED EXCEPT EXISTS EXIT
+	EXPLAIN FALSE FETCH FIRST_VALUE FLOAT FLOAT4 FLOAT8 FOR FORCE FOREIGN FROM FULLTEXT FUNCTION
+	GENERATED GET GRANT GROUP GROUPING GROUPS HAVING HIGH_PRIORITY HOUR_MICROSECOND HOUR_MINUTE
+	HOUR_SECOND IF IGNORE IN INDEX INFILE INNER INOUT INSENSITIVE INSERT INT INT1 INT2 INT3 INT4
+	INT8 INTEGER INTERSECT INTERVAL INTO IO_AFTER_GTIDS IO_BEFORE_GTIDS IS ITERATE JOIN JSON_TABLE
+	KEY KEYS KILL LAG LAST_VALUE LATERAL LEAD LEADING LEAVE LEFT LIKE LIMIT LINEAR LINES LOAD
+	LOCALTIME LOCALTIMESTAMP LOCK LONG LONGBLOB LONGTEXT LOOP LOW_PRIORITY MASTER_BIND
+	MASTER_SSL_VERIFY_SERVER_CERT MATCH MAXVALUE MEDIUMBLOB MEDIUMINT MEDIUMTEXT MIDDLEINT
+	MINUTE_MICROSECOND MINUTE_SECOND MOD MODIFIES NATURAL NOT NO_WRITE_TO_BINLOG NTH_VALUE NTILE
+	NULL NUMERIC OF ON OPTIMIZE OPTIMIZER_COSTS OPTION OPTIONALLY OR ORDER OUT OUTER OUTFILE OVER
+	PARTITION PERCENT_RANK PRECISION PRIMARY PROCEDURE PURGE RANGE RANK READ READS READ_WRITE REAL
+	RECURSIVE REFERENCES REGEXP RELEASE RENAME REPEAT REPLACE REQUIRE RESIGNAL RESTRICT RETURN
+	REVOKE RIGHT RLIKE ROW ROWS ROW_NUMBER SCHEMA SCHEMAS SECOND_MICROSECOND SELECT SENSITIVE
+	SEPARATOR SET SHOW SIGNAL SMALLINT SPATIAL SPECIFIC SQL SQLEXCEPTION SQLSTATE SQLWARNING
+	SQL_BIG_RESULT SQL_CALC_FOUND_ROWS SQL_SMALL_RESULT SSL STARTING STORED STRAIGHT_JOIN SYSTEM
+	TABLE TERMINATED THEN TINYBLOB TINYINT TINYTEXT TO TRAILING TRIGGER TRUE UNDO UNION UNIQUE
+	UNLOCK UNSIGNED UPDATE USAGE USE USING UTC_DATE UTC_TIME UTC_TIMESTAMP VALUES VARBINARY
+	VARCHAR VARCHARACTER VARYING VIRTUAL WHEN WHERE WHILE WINDOW WITH WRITE XOR YEAR_MONTH
+	ZEROFILL
+`))
+
+// SchemaIssue é um problema encontrado no arquivo de schema
+type SchemaIssue struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// SchemaError reúne todos os problemas encontrados ao validar um arquivo de schema
+type SchemaError struct {
+	Path   string
+	Issues []SchemaIssue
+}
+
+// Error lista os problemas no formato arquivo:linha:coluna: mensagem, um por linha
+func (e *SchemaError) Error() string {
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		lines[i] = fmt.Sprintf("%s:%d:%d: %s", e.Path, issue.Line, issue.Column, issue.Message)
+	}
+	return fmt.Sprintf("schema inválido (%d problema(s)):\n%s", len(e.Issues), strings.Join(lines, "\n"))
+}
+
+// schemaLinter acumula os problemas encontrados ao percorrer a árvore do schema
+type schemaLinter struct {
+	issues []SchemaIssue
+}
+
+// lintSchemaNode valida a árvore do schema: estrutura (chaves e tipos dos valores,
+// deduzidos das tags json das structs) e regras de negócio (tipos, chaves, regex, máscaras...)
+func lintSchemaNode(root *schemaNode) []SchemaIssue {
+	l := &schemaLinter{}
+	l.checkStructure(root, reflect.TypeOf(Schema{}), "")
+	if root.kind == nodeObject {
+		l.checkSchema(root)
+	}
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	return l.issues
+}
+
+func (l *schemaLinter) addf(n *schemaNode, format string, args ...interface{}) {
+	l.issues = append(l.issues, SchemaIssue{Line: n.line, Column: n.column, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkStructure confere o nó contra o tipo Go que ele vai preencher: chaves desconhecidas
+// em objetos e valores do tipo errado (ex.: "required": "sim"). context é o caminho do nó.
+func (l *schemaLinter) checkStructure(n *schemaNode, t reflect.Type, context string) {
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.kind != nodeObject {
+			l.addf(n, "%sdeve ser um objeto", at(context))
+			return
+		}
+		known := jsonFields(t)
+		seen := map[string]bool{}
+		for i, key := range n.keys {
+			if seen[key.text] {
+				l.addf(key, "%schave %q repetida", at(context), key.text)
+			}
+			seen[key.text] = true
+
+			fieldType, ok := known[key.text]
+			if !ok {
+				l.addf(key, "%schave desconhecida %q%s", at(context), key.text, suggestion(key.text, t))
+				continue
+			}
+			l.checkStructure(n.values[i], fieldType, strings.TrimPrefix(context+"."+key.text, "."))
+		}
+	case reflect.Slice:
+		if n.kind == nodeNull {
+			return
+		}
+		if n.kind != nodeArray {
+			l.addf(n, "%sdeve ser uma lista", at(context))
+			return
+		}
+		for i, item := range n.values {
+			l.checkStructure(item, t.Elem(), fmt.Sprintf("%s[%d]", context, i+1))
+		}
+	case reflect.String:
+		if n.kind != nodeString {
+			l.addf(n, "%sdeve ser um texto", at(context))
+		}
+	case reflect.Bool:
+		if n.kind != nodeBool {
+			l.addf(n, "%sdeve ser true ou false", at(context))
+		}
+	case reflect.Int, reflect.Int64:
+		if n.kind != nodeNumber || strings.ContainsAny(n.text, ".eE") {
+			l.addf(n, "%sdeve ser um número inteiro", at(context))
+		}
+	case reflect.Float64:
+		if n.kind != nodeNumber {
+			l.addf(n, "%sdeve ser um número", at(context))
+		}
+	}
+}
+
+// at formata o caminho do valor (ex.: "fields[2].required") como prefixo da mensagem
+func at(context string) string {
+	if context == "" {
+		return ""
+	}
+	return context + ": "
+}
+
+// checkSchema aplica as regras que dependem do significado dos valores
+func (l *schemaLinter) checkSchema(root *schemaNode) {
+	tableName := root.field("table_name")
+	if tableName.str() == "" {
+		l.addf(keyOrSelf(root, "table_name"), "table_name é obrigatório")
+	} else if strings.EqualFold(tableName.str(), MigrationsTable) {
+		l.addf(tableName, "table_name: %q é usada pelo histórico de migrações; escolha outro nome", tableName.str())
+	} else {
+		l.checkIdentifier(tableName, "table_name")
+	}
+
+	fields := root.field("fields")
+	if fields == nil || fields.kind != nodeArray || len(fields.values) == 0 {
+		l.addf(keyOrSelf(root, "fields"), "o schema deve ter ao menos um campo em \"fields\"")
+		return
+	}
+
+	names := map[string]bool{}
+	fieldTypes := map[string]string{} // Nome exato → tipo, para as regras
+	primaryKeys := 0
+	for i, fieldNode := range fields.values {
+		if fieldNode.kind != nodeObject {
+			continue // Já reportado pela estrutura
+		}
+		context := fmt.Sprintf("campo %d", i+1)
+		if name := fieldNode.field("name").str(); name != "" {
+			context = fmt.Sprintf("campo %q", name)
+			lower := strings.ToLower(name)
+			if names[lower] {
+				l.addf(fieldNode.field("name"), "%s: nome duplicado (nomes de coluna não diferenciam maiúsculas)", context)
+			}
+			names[lower] = true
+			fieldTypes[name] = fieldNode.field("type").str()
+		}
+		if fieldNode.field("primary_key").isTrue() {
+			primaryKeys++
+		}
+		l.checkField(fieldNode, context)
+	}
+
+	if primaryKeys == 0 {
+		l.addf(keyOrSelf(root, "fields"), "nenhum campo com \"primary_key\": true (Update e Delete precisam da chave)")
+	}
+
+	if versionField := root.field("version_field"); versionField.str() != "" {
+		l.checkIdentifier(versionField, "version_field")
+		if names[strings.ToLower(versionField.str())] {
+			l.addf(versionField, "version_field: %q já é um campo do schema; use uma coluna exclusiva para a versão", versionField.str())
+		}
+	}
+
+	if rules := root.field("rules"); rules != nil && rules.kind == nodeArray {
+		for i, ruleNode := range rules.values {
+			if ruleNode.kind == nodeObject {
+				l.checkRule(ruleNode, fmt.Sprintf("rules[%d]", i+1), fieldTypes)
+			}
+		}
+	}
+
+	for _, key := range []string{"primary_color", "background_color"} {
+		if color := root.field("theme").field(key); color.str() != "" && !IsValidColor(color.str()) {
+			l.addf(color, "theme.%s: cor inválida %q (use #rgb, #rrggbb ou um nome, ex.: teal)", key, color.str())
+		}
+	}
+}
+
+// checkField valida um item de "fields"
+func (l *schemaLinter) checkField(n *schemaNode, context string) {
+	nameNode := n.field("name")
+	if nameNode.str() == "" {
+		l.addf(keyOrSelf(n, "name"), "%s: \"name\" é obrigatório", context)
+	} else {
+		l.checkIdentifier(nameNode, context)
+	}
+
+	field := Field{Type: n.field("type").str(), Mask: n.field("mask").str()}
+	typeNode := n.field("type")
+	if field.Type == "" {
+		l.addf(keyOrSelf(n, "type"), "%s: \"type\" é obrigatório", context)
+	} else if !slices.Contains(FieldTypes, field.BaseType()) {
+		l.addf(typeNode, "%s: tipo desconhecido %q (tipos aceitos: %s)%s",
+			context, field.Type, strings.Join(FieldTypes, ", "), closest(field.BaseType(), FieldTypes))
+	} else if field.BaseType() == "decimal" {
+		if _, _, err := field.DecimalSpec(); err != nil {
+			l.addf(typeNode, "%s: %v", context, err)
+		}
+	} else if field.Type != field.BaseType() {
+		l.addf(typeNode, "%s: o tipo %q não aceita parâmetros", context, field.BaseType())
+	}
+
+	if n.field("primary_key").isTrue() && slices.Contains(nonKeyTypes, field.BaseType()) {
+		l.addf(n.field("primary_key"), "%s: campos do tipo %q não podem ser chave primária", context, field.Type)
+	}
+	if n.field("unique").isTrue() && slices.Contains(nonKeyTypes, field.BaseType()) {
+		l.addf(n.field("unique"), "%s: campos do tipo %q não podem ser unique", context, field.Type)
+	}
+
+	if searchable := n.field("searchable"); searchable.isTrue() {
+		if base := field.BaseType(); base != "string" && base != "text" && slices.Contains(FieldTypes, base) {
+			l.addf(searchable, "%s: \"searchable\" só se aplica a campos string ou text", context)
+		}
+	}
+
+	if length := n.field("length"); length != nil && length.kind == nodeNumber {
+		if field.BaseType() != "string" && slices.Contains(FieldTypes, field.BaseType()) {
+			l.addf(keyOrSelf(n, "length"), "%s: \"length\" só se aplica a campos string", context)
+		} else if size, err := strconv.Atoi(length.text); err == nil && (size < 1 || size > maxVarcharLength) {
+			l.addf(length, "%s: \"length\" deve estar entre 1 e %d", context, maxVarcharLength)
+		}
+	}
+
+	if validation := n.field("validation"); validation != nil {
+		l.checkValidation(validation, context)
+	}
+
+	if maskNode := n.field("mask"); field.Mask != "" {
+		// Só string/text têm a máscara removida antes de salvar (ver validators.CleanValueByMask)
+		if base := field.BaseType(); base != "string" && base != "text" && slices.Contains(FieldTypes, base) {
+			l.addf(maskNode, "%s: máscara só se aplica a campos string ou text", context)
+		} else if err := checkMask(field.Mask); err != nil {
+			l.addf(maskNode, "%s: máscara %q inválida: %v", context, field.Mask, err)
+		}
+	}
+
+	if enum := n.field("enum"); enum != nil && enum.kind == nodeArray {
+		if len(enum.values) == 0 {
+			l.addf(enum, "%s: \"enum\" vazio; remova a chave ou informe os valores permitidos", context)
+		}
+		seen := map[string]bool{}
+		for _, item := range enum.values {
+			if item.kind != nodeString {
+				continue
+			}
+			if item.text == "" {
+				l.addf(item, "%s: valores do enum não podem ser vazios", context)
+			} else if seen[item.text] {
+				l.addf(item, "%s: valor %q repetido no enum", context, item.text)
+			}
+			seen[item.text] = true
+		}
+	}
+
+	if widget := n.field("widget"); widget.str() != "" && !slices.Contains(Widgets, widget.str()) {
+		l.addf(widget, "%s: widget desconhecido %q (widgets aceitos: %s)%s",
+			context, widget.str(), strings.Join(Widgets, ", "), closest(widget.str(), Widgets))
+	}
+
+	// Opções de upload só fazem sentido em campos file/image
+	for _, key := range []string{"max_size_mb", "accept"} {
+		value := n.field(key)
+		if value == nil {
+			continue
+		}
+		if !field.IsUpload() {
+			l.addf(keyOrSelf(n, key), "%s: %q só se aplica a campos do tipo file ou image", context, key)
+			continue
+		}
+		if key == "max_size_mb" && value.kind == nodeNumber && (strings.HasPrefix(value.text, "-") || value.text == "0") {
+			l.addf(value, "%s: \"max_size_mb\" deve ser maior que zero", context)
+		}
+		if key == "accept" {
+			for _, item := range value.values {
+				if item.kind == nodeString && !strings.Contains(item.text, "/") {
+					l.addf(item, "%s: tipo MIME %q inválido (ex.: \"image/*\", \"application/pdf\")", context, item.text)
+				}
+			}
+		}
+	}
+}
+
+// checkRule valida um item de "rules": as expressões precisam ser válidas e citar só campos
+// do schema (uploads ficam de fora: o valor deles não passa pela validação do registro)
+func (l *schemaLinter) checkRule(n *schemaNode, context string, fieldTypes map[string]string) {
+	names := make([]string, 0, len(fieldTypes))
+	for name := range fieldTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checkName := func(node *schemaNode, name string) {
+		if fieldType, ok := fieldTypes[name]; !ok {
+			l.addf(node, "%s: campo desconhecido %q%s", context, name, closest(name, names))
+		} else if (Field{Type: fieldType}).IsUpload() {
+			l.addf(node, "%s: campos do tipo %q não podem ser usados em regras", context, fieldType)
+		}
+	}
+
+	if n.field("check").str() == "" {
+		l.addf(keyOrSelf(n, "check"), "%s: \"check\" é obrigatório", context)
+	}
+	if n.field("message").str() == "" {
+		l.addf(keyOrSelf(n, "message"), "%s: \"message\" é obrigatório (é o erro exibido ao usuário)", context)
+	}
+	for _, key := range []string{"when", "check"} {
+		exprNode := n.field(key)
+		if exprNode.str() == "" {
+			continue
+		}
+		expr, err := ParseRuleExpr(exprNode.str())
+		if err != nil {
+			l.addf(exprNode, "%s.%s: %v", context, key, err)
+			continue
+		}
+		for _, name := range expr.Idents() {
+			checkName(exprNode, name)
+		}
+	}
+	if fields := n.field("fields"); fields != nil && fields.kind == nodeArray {
+		for _, item := range fields.values {
+			if item.kind == nodeString {
+				checkName(item, item.text)
+			}
+		}
+	}
+}
+
+// checkValidation valida o objeto "validation" de um campo
+func (l *schemaLinter) checkValidation(n *schemaNode, context string) {
+	if typeNode := n.field("type"); typeNode.str() != "" && !slices.Contains(ValidationTypes, typeNode.str()) {
+		l.addf(typeNode, "%s: tipo de validação desconhecido %q (tipos aceitos: %s)%s",
+			context, typeNode.str(), strings.Join(ValidationTypes, ", "), closest(typeNode.str(), ValidationTypes))
+	}
+
+	rules := n.field("regex_rules")
+	if rules == nil || rules.kind != nodeArray {
+		return
+	}
+	for i, rule := range rules.values {
+		if rule.kind != nodeObject {
+			continue
+		}
+		ruleContext := fmt.Sprintf("%s, regex_rules[%d]", context, i+1)
+
+		pattern := rule.field("pattern")
+		if pattern.str() == "" {
+			l.addf(keyOrSelf(rule, "pattern"), "%s: \"pattern\" é obrigatório", ruleContext)
+		} else if _, err := regexp.Compile(pattern.str()); err != nil {
+			l.addf(pattern, "%s: regex inválida: %v", ruleContext, err)
+		}
+		if rule.field("message").str() == "" {
+			l.addf(keyOrSelf(rule, "message"), "%s: \"message\" é obrigatório (é o erro exibido ao usuário)", ruleContext)
+		}
+	}
+}
+
+// checkIdentifier garante que o nome pode ser usado como tabela/coluna sem aspas
+func (l *schemaLinter) checkIdentifier(n *schemaNode, context string) {
+	name := n.str()
+	switch {
+	case !identifierRegex.MatchString(name):
+		l.addf(n, "%s: nome %q inválido (use letras, números e _, começando por letra ou _)", context, name)
+	case len(name) > maxIdentifierLength:
+		l.addf(n, "%s: nome %q excede %d caracteres", context, name, maxIdentifierLength)
+	case sqlReservedWords[strings.ToUpper(name)]:
+		l.addf(n, "%s: %q é palavra reservada do SQL; escolha outro nome", context, name)
+	}
+}
+
+// checkMask valida uma máscara: precisa de ao menos um marcador (9 dígito, # letra, * qualquer)
+func checkMask(mask string) error {
+	if !strings.ContainsAny(mask, "9#*") {
+		return fmt.Errorf("nenhum marcador (9 dígito, # letra, * qualquer)")
+	}
+	return nil
+}
+
+// keyOrSelf retorna o nó da chave (para apontar onde ela está) ou o próprio objeto se ela faltar
+func keyOrSelf(n *schemaNode, key string) *schemaNode {
+	for _, k := range n.keys {
+		if k.text == key {
+			return k
+		}
+	}
+	return n
+}
+
+// jsonFields mapeia o nome json de cada campo exportado da struct para o seu tipo
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// suggestion sugere a chave conhecida mais parecida com a digitada
+func suggestion(key string, t reflect.Type) string {
+	names := []string{}
+	for name := range jsonFields(t) {
+		names = append(names, name)
+	}
+	return closest(key, names)
+}
+
+// closest retorna " (você quis dizer ...?)" para a opção a até 2 edições de distância
+func closest(value string, options []string) string {
+	best, bestDistance := "", 3
+	for _, option := range options {
+		if d := editDistance(strings.ToLower(value), option); d < bestDistance || d == bestDistance && option < best {
+			best, bestDistance = option, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (você quis dizer %q?)", best)
+}
+
+// editDistance calcula a distância de Levenshtein entre duas strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr := make([]int, len(rb)+1)
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(rb)]
+}
+
+func toSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lintJSON valida um schema JSON e retorna só as mensagens (as posições ficam em TestLintPositions)
+func lintJSON(t *testing.T, src string) []string {
+	t.Helper()
+	root, issues := parseJSONNode([]byte(src))
+	if root == nil {
+		t.Fatalf("JSON inválido: %v\n%s", issues, src)
+	}
+	messages := []string{}
+	for _, issue := range lintSchemaNode(root) {
+		messages = append(messages, issue.Message)
+	}
+	return messages
+}
+
+// withFields monta um schema válido com a chave id e os campos informados
+func withFields(fields ...string) string {
+	return `{"table_name": "pedidos", "fields": [{"name": "id", "type": "int", "primary_key": true}` +
+		strings.Join(append([]string{""}, fields...), ", ") + `]}`
+}
+
+func TestLintSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   []string
+	}{
+		{"válido", withFields(`{"name": "nome", "type": "string", "required": true}`), []string{}},
+
+		// Chaves desconhecidas
+		{"chave desconhecida no topo", `{"table_name": "t", "campos": [], "fields": [{"name": "id", "type": "int", "primary_key": true}]}`,
+			[]string{`chave desconhecida "campos"`}},
+		{"chave parecida", withFields(`{"name": "cpf", "type": "string", "requird": true}`),
+			[]string{`fields[2]: chave desconhecida "requird" (você quis dizer "required"?)`}},
+		{"chave desconhecida aninhada", withFields(`{"name": "cpf", "type": "string", "validation": {"tipo": "cpf"}}`),
+			[]string{`fields[2].validation: chave desconhecida "tipo" (você quis dizer "type"?)`}},
+		{"chave repetida", withFields(`{"name": "cpf", "type": "string", "type": "text"}`),
+			[]string{`fields[2]: chave "type" repetida`}},
+
+		// Tipos dos valores
+		{"bool como texto", withFields(`{"name": "cpf", "type": "string", "required": "sim"}`),
+			[]string{`fields[2].required: deve ser true ou false`}},
+		{"texto como número", withFields(`{"name": "cpf", "type": 5}`),
+			[]string{`campo "cpf": "type" é obrigatório`, `fields[2].type: deve ser um texto`}},
+		{"lista como objeto", withFields(`{"name": "status", "type": "string", "enum": {"a": 1}}`),
+			[]string{`fields[2].enum: deve ser uma lista`}},
+		{"fields não é lista", `{"table_name": "t", "fields": {"name": "id"}}`,
+			[]string{`o schema deve ter ao menos um campo em "fields"`, `fields: deve ser uma lista`}},
+		{"tipo de campo desconhecido", withFields(`{"name": "cpf", "type": "strng"}`),
+			[]string{`campo "cpf": tipo desconhecido "strng" (tipos aceitos: ` + strings.Join(FieldTypes, ", ") + `) (você quis dizer "string"?)`}},
+
+		// Nomes duplicados
+		{"nome duplicado", withFields(`{"name": "nome", "type": "string"}`, `{"name": "nome", "type": "text"}`),
+			[]string{`campo "nome": nome duplicado (nomes de coluna não diferenciam maiúsculas)`}},
+		{"nome duplicado com outra caixa", withFields(`{"name": "Nome", "type": "string"}`, `{"name": "NOME", "type": "string"}`),
+			[]string{`campo "NOME": nome duplicado (nomes de coluna não diferenciam maiúsculas)`}},
+		{"version_field repetindo campo", `{"table_name": "t", "version_field": "id", "fields": [{"name": "id", "type": "int", "primary_key": true}]}`,
+			[]string{`version_field: "id" já é um campo do schema; use uma coluna exclusiva para a versão`}},
+		{"valor repetido no enum", withFields(`{"name": "status", "type": "string", "enum": ["a", "b", "a"]}`),
+			[]string{`campo "status": valor "a" repetido no enum`}},
+
+		// Regex
+		{"regex inválida", withFields(`{"name": "cod", "type": "string", "validation": {"regex_rules": [{"pattern": "^[A-Z", "message": "x"}]}}`),
+			[]string{`campo "cod", regex_rules[1]: regex inválida: error parsing regexp: missing closing ]: ` + "`[A-Z`"}},
+		{"regex sem mensagem", withFields(`{"name": "cod", "type": "string", "validation": {"regex_rules": [{"pattern": "^a$"}]}}`),
+			[]string{`campo "cod", regex_rules[1]: "message" é obrigatório (é o erro exibido ao usuário)`}},
+
+		// Palavras reservadas e identificadores
+		{"tabela reservada", `{"table_name": "order", "fields": [{"name": "id", "type": "int", "primary_key": true}]}`,
+			[]string{`table_name: "order" é palavra reservada do SQL; escolha outro nome`}},
+		{"campo reservado", withFields(`{"name": "Select", "type": "string"}`),
+			[]string{`campo "Select": "Select" é palavra reservada do SQL; escolha outro nome`}},
+		{"tabela do histórico", `{"table_name": "schema_migrations", "fields": [{"name": "id", "type": "int", "primary_key": true}]}`,
+			[]string{`table_name: "schema_migrations" é usada pelo histórico de migrações; escolha outro nome`}},
+		{"nome inválido", withFields(`{"name": "data-nascimento", "type": "date"}`),
+			[]string{`campo "data-nascimento": nome "data-nascimento" inválido (use letras, números e _, começando por letra ou _)`}},
+		{"nome longo", withFields(`{"name": "` + strings.Repeat("a", 65) + `", "type": "int"}`),
+			[]string{`campo "` + strings.Repeat("a", 65) + `": nome "` + strings.Repeat("a", 65) + `" excede 64 caracteres`}},
+	}
+	for _, tt := range tests {
+		if got := lintJSON(t, tt.schema); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n problemas = %q\n esperava  %q", tt.name, got, tt.want)
+		}
+	}
+}
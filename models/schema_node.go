@@ -0,0 +1,345 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// nodeKind é o tipo de um valor do arquivo de schema
+type nodeKind int
+
+const (
+	nodeObject nodeKind = iota
+	nodeArray
+	nodeString
+	nodeNumber
+	nodeBool
+	nodeNull
+)
+
+// schemaNode é um valor do arquivo de schema com a linha e a coluna em que aparece.
+// A validação trabalha sobre essa árvore (e não sobre a struct) para poder apontar a
+// posição exata de cada problema, independente do formato do arquivo.
+type schemaNode struct {
+	kind   nodeKind
+	line   int
+	column int
+	keys   []*schemaNode // Objetos: nós das chaves, alinhados com values
+	values []*schemaNode // Valores do objeto ou itens do array
+	text   string        // Valor de strings, números e booleanos
+}
+
+// field retorna o valor da chave de um objeto (nil se ausente)
+func (n *schemaNode) field(key string) *schemaNode {
+	if n == nil || n.kind != nodeObject {
+		return nil
+	}
+	for i, k := range n.keys {
+		if k.text == key {
+			return n.values[i]
+		}
+	}
+	return nil
+}
+
+// str retorna o texto do nó se ele for string ("" caso contrário)
+func (n *schemaNode) str() string {
+	if n == nil || n.kind != nodeString {
+		return ""
+	}
+	return n.text
+}
+
+// isTrue indica se o nó é o booleano true
+func (n *schemaNode) isTrue() bool {
+	return n != nil && n.kind == nodeBool && n.text == "true"
+}
+
+// toInterface converte a árvore para os tipos de encoding/json (map, slice, string...)
+func (n *schemaNode) toInterface() interface{} {
+	switch n.kind {
+	case nodeObject:
+		m := make(map[string]interface{}, len(n.keys))
+		for i, k := range n.keys {
+			m[k.text] = n.values[i].toInterface()
+		}
+		return m
+	case nodeArray:
+		items := make([]interface{}, len(n.values))
+		for i, v := range n.values {
+			items[i] = v.toInterface()
+		}
+		return items
+	case nodeString:
+		return n.text
+	case nodeNumber:
+		return json.Number(n.text)
+	case nodeBool:
+		return n.text == "true"
+	}
+	return nil
+}
+
+// decodeSchemaNode preenche o Schema a partir da árvore já validada
+func decodeSchemaNode(root *schemaNode) (*Schema, error) {
+	raw, err := json.Marshal(root.toInterface())
+	if err != nil {
+		return nil, err
+	}
+	var schema Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+	if err := schema.compileRegexRules(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// nodeFromValue monta a árvore a partir de uma struct do schema, usando os nomes das
+// tags json e omitindo os campos com valor zero
+func nodeFromValue(v reflect.Value) *schemaNode {
+	switch v.Kind() {
+	case reflect.Struct:
+		node := &schemaNode{kind: nodeObject}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" || !f.IsExported() || v.Field(i).IsZero() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			node.keys = append(node.keys, &schemaNode{kind: nodeString, text: name})
+			node.values = append(node.values, nodeFromValue(v.Field(i)))
+		}
+		return node
+	case reflect.Slice:
+		node := &schemaNode{kind: nodeArray}
+		for i := 0; i < v.Len(); i++ {
+			node.values = append(node.values, nodeFromValue(v.Index(i)))
+		}
+		return node
+	case reflect.Bool:
+		return &schemaNode{kind: nodeBool, text: strconv.FormatBool(v.Bool())}
+	case reflect.Int, reflect.Int64:
+		return &schemaNode{kind: nodeNumber, text: strconv.FormatInt(v.Int(), 10)}
+	case reflect.Float64:
+		return &schemaNode{kind: nodeNumber, text: strconv.FormatFloat(v.Float(), 'f', -1, 64)}
+	case reflect.String:
+		return &schemaNode{kind: nodeString, text: v.String()}
+	}
+	return &schemaNode{kind: nodeNull}
+}
+
+// encodeJSONNode escreve a árvore como JSON indentado, mantendo a ordem das chaves;
+// listas de valores simples (ex.: enum) ficam em uma linha
+func encodeJSONNode(root *schemaNode) []byte {
+	var buf bytes.Buffer
+	writeJSONNode(&buf, root, "")
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func writeJSONNode(buf *bytes.Buffer, n *schemaNode, indent string) {
+	switch n.kind {
+	case nodeObject:
+		if len(n.keys) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i, key := range n.keys {
+			buf.WriteString(indent + "  ")
+			writeJSONString(buf, key.text)
+			buf.WriteString(": ")
+			writeJSONNode(buf, n.values[i], indent+"  ")
+			if i < len(n.keys)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+	case nodeArray:
+		nested := false
+		for _, item := range n.values {
+			if item.kind == nodeObject || item.kind == nodeArray {
+				nested = true
+			}
+		}
+		if !nested {
+			buf.WriteByte('[')
+			for i, item := range n.values {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				writeJSONNode(buf, item, indent)
+			}
+			buf.WriteByte(']')
+			return
+		}
+		buf.WriteString("[\n")
+		for i, item := range n.values {
+			buf.WriteString(indent + "  ")
+			writeJSONNode(buf, item, indent+"  ")
+			if i < len(n.values)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+	case nodeString:
+		writeJSONString(buf, n.text)
+	case nodeNumber, nodeBool:
+		buf.WriteString(n.text)
+	default:
+		buf.WriteString("null")
+	}
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	buf.Truncate(buf.Len() - 1) // Encode termina com quebra de linha
+}
+
+// jsonNodeParser monta a árvore de um JSON lendo token a token e guardando a posição
+// de cada um (o decoder informa o offset do fim do token; o início é obtido pulando
+// espaços e separadores a partir do fim do token anterior)
+type jsonNodeParser struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+// parseJSONNode lê um documento JSON como árvore de schemaNode
+func parseJSONNode(data []byte) (*schemaNode, []SchemaIssue) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM do UTF-8
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	p := &jsonNodeParser{data: data, dec: dec}
+
+	root, err := p.value()
+	if err != nil {
+		return nil, []SchemaIssue{p.syntaxIssue(err)}
+	}
+
+	offset := p.tokenStart()
+	if _, err := dec.Token(); err != io.EOF {
+		line, column := offsetPosition(data, offset)
+		return nil, []SchemaIssue{{Line: line, Column: column, Message: "JSON inválido: conteúdo após o fim do documento"}}
+	}
+	return root, nil
+}
+
+// value lê o próximo valor (escalar, objeto ou array)
+func (p *jsonNodeParser) value() (*schemaNode, error) {
+	offset := p.tokenStart()
+	tok, err := p.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	line, column := offsetPosition(p.data, offset)
+	node := &schemaNode{line: line, column: column}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			node.kind = nodeObject
+			for p.dec.More() {
+				keyOffset := p.tokenStart()
+				keyTok, err := p.dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				keyLine, keyColumn := offsetPosition(p.data, keyOffset)
+				key := &schemaNode{kind: nodeString, line: keyLine, column: keyColumn, text: keyTok.(string)}
+
+				val, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key)
+				node.values = append(node.values, val)
+			}
+		case '[':
+			node.kind = nodeArray
+			for p.dec.More() {
+				item, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.values = append(node.values, item)
+			}
+		}
+		// Consome o '}' ou ']' de fechamento
+		if _, err := p.dec.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.kind = nodeString
+		node.text = t
+	case json.Number:
+		node.kind = nodeNumber
+		node.text = t.String()
+	case bool:
+		node.kind = nodeBool
+		node.text = strconv.FormatBool(t)
+	case nil:
+		node.kind = nodeNull
+	}
+	return node, nil
+}
+
+// tokenStart retorna o offset do início do próximo token
+func (p *jsonNodeParser) tokenStart() int {
+	offset := int(p.dec.InputOffset())
+	for offset < len(p.data) {
+		switch p.data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+			continue
+		}
+		break
+	}
+	return offset
+}
+
+// syntaxIssue converte um erro do decoder em um problema com posição
+func (p *jsonNodeParser) syntaxIssue(err error) SchemaIssue {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// Offset conta o caractere inválido; a posição é a dele
+		line, column := offsetPosition(p.data, max(int(syntaxErr.Offset)-1, 0))
+		return SchemaIssue{Line: line, Column: column, Message: "JSON inválido: " + syntaxErr.Error()}
+	case err == io.EOF && len(bytes.TrimSpace(p.data)) == 0:
+		return SchemaIssue{Line: 1, Column: 1, Message: "arquivo vazio"}
+	case err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF):
+		line, column := offsetPosition(p.data, len(p.data))
+		return SchemaIssue{Line: line, Column: column, Message: "JSON inválido: fim inesperado do arquivo"}
+	}
+	line, column := offsetPosition(p.data, int(p.dec.InputOffset()))
+	return SchemaIssue{Line: line, Column: column, Message: fmt.Sprintf("JSON inválido: %v", err)}
+}
+
+// offsetPosition converte um offset em bytes para linha e coluna (contadas em caracteres, a partir de 1)
+func offsetPosition(data []byte, offset int) (line, column int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	column = utf8.RuneCount(before[lineStart:]) + 1
+	return line, column
+}
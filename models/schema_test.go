@@ -0,0 +1,198 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// parseSchemaData lê o schema no formato indicado, como readSchemaNode faz pela extensão
+func parseSchemaData(format string, data []byte) (*schemaNode, []SchemaIssue) {
+	switch format {
+	case "yaml":
+		return parseYAMLNode(data)
+	case "toml":
+		return parseTOMLNode(data)
+	}
+	return parseJSONNode(data)
+}
+
+// encodeSchemaData escreve a árvore no formato indicado, como writeSchemaNode
+func encodeSchemaData(format string, root *schemaNode) ([]byte, error) {
+	switch format {
+	case "yaml":
+		return encodeYAMLNode(root)
+	case "toml":
+		return encodeTOMLNode(root)
+	}
+	return encodeJSONNode(root), nil
+}
+
+func TestSchemaRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("testdata/schema_roundtrip.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, issues := parseJSONNode(data)
+	if len(issues) > 0 || len(lintSchemaNode(original)) > 0 {
+		t.Fatalf("schema de teste inválido: %v %v", issues, lintSchemaNode(original))
+	}
+	want := encodeJSONNode(original)
+	wantSchema, err := decodeSchemaNode(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range [][]string{
+		{"yaml", "json"},
+		{"toml", "json"},
+		{"yaml", "toml", "json"},
+		{"toml", "yaml", "json"},
+		{"toml", "toml", "json"},
+		{"yaml", "yaml", "json"},
+	} {
+		node := original
+		for _, format := range path {
+			out, err := encodeSchemaData(format, node)
+			if err != nil {
+				t.Fatalf("%v: gerar %s: %v", path, format, err)
+			}
+			var issues []SchemaIssue
+			node, issues = parseSchemaData(format, out)
+			if len(issues) > 0 {
+				t.Fatalf("%v: ler o %s gerado: %v\n%s", path, format, issues, out)
+			}
+			if issues := lintSchemaNode(node); len(issues) > 0 {
+				t.Fatalf("%v: o %s gerado não passa na validação: %v\n%s", path, format, issues, out)
+			}
+		}
+		if got := encodeJSONNode(node); string(got) != string(want) {
+			t.Errorf("%v: a conversão alterou o schema:\n%s\nesperava:\n%s", path, got, want)
+		}
+		schema, err := decodeSchemaNode(node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(schema, wantSchema) {
+			t.Errorf("%v: schema = %+v, esperava %+v", path, schema, wantSchema)
+		}
+	}
+}
+
+func TestWriteSchemaOmitsDefaults(t *testing.T) {
+	schema := &Schema{TableName: "t", Fields: []Field{{Name: "id", Type: "int", PrimaryKey: true}}}
+	got := string(encodeJSONNode(nodeFromValue(reflect.ValueOf(*schema))))
+	want := `{
+  "table_name": "t",
+  "fields": [
+    {
+      "name": "id",
+      "type": "int",
+      "primary_key": true
+    }
+  ]
+}
+`
+	if got != want {
+		t.Errorf("nodeFromValue:\n%s\nesperava:\n%s", got, want)
+	}
+}
+
+// Os mesmos problemas, em cada formato, apontam a linha e a coluna do valor (ou da chave,
+// quando a chave é o problema)
+func TestLintPositions(t *testing.T) {
+	tests := []struct {
+		format string
+		src    string
+		want   []string
+	}{
+		{"json", `{
+  "table_name": "t",
+  "fields": [
+    {"name": "id", "type": "int", "primary_key": true, "required": "sim"},
+    {"name": "nome", "type": "string", "mascara": "99"}
+  ]
+}`, []string{
+			`4:68: fields[1].required: deve ser true ou false`,
+			`5:40: fields[2]: chave desconhecida "mascara"`,
+		}},
+		{"yaml", `table_name: t
+fields:
+  - name: id
+    type: int
+    primary_key: true
+    required: sim
+  - name: nome
+    type: string
+    mascara: "99"
+`, []string{
+			`6:15: fields[1].required: deve ser true ou false`,
+			`9:5: fields[2]: chave desconhecida "mascara"`,
+		}},
+		{"toml", `table_name = "t"
+
+[[fields]]
+name = "id"
+type = "int"
+primary_key = true
+required   =   "sim"
+
+[[fields]]
+name = "nome"
+type = "string"
+mascara = "99"
+`, []string{
+			`7:16: fields[1].required: deve ser true ou false`,
+			`12:1: fields[2]: chave desconhecida "mascara"`,
+		}},
+		{"toml", `table_name = "t"
+fields = [
+  { name = "id", type = "int", primary_key = true, validation = { type = 5 } },
+]
+`, []string{
+			`3:74: fields[1].validation.type: deve ser um texto`,
+		}},
+		{"json", `{"table_name": "t", "fields": [}`, []string{
+			`1:32: JSON inválido: invalid character '}' looking for beginning of value`,
+		}},
+		{"toml", "table_name = \"t\"\nfields = [\n", []string{
+			`2:11: TOML inválido: unexpected EOF; expected value`,
+		}},
+	}
+	for _, tt := range tests {
+		root, issues := parseSchemaData(tt.format, []byte(tt.src))
+		if root != nil {
+			issues = lintSchemaNode(root)
+		}
+		got := []string{}
+		for _, issue := range issues {
+			got = append(got, fmt.Sprintf("%d:%d: %s", issue.Line, issue.Column, issue.Message))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n%s\nproblemas = %q\nesperava %q", tt.format, tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestRegexRulesCompiledOnLoad(t *testing.T) {
+	schema, err := LoadSchema("testdata/schema_roundtrip.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	field, _ := schema.FieldByName("codigo")
+	rules := field.Validation.RegexRules
+	for _, rule := range rules {
+		if rule.re == nil {
+			t.Errorf("regex %q não foi compilada ao carregar o schema", rule.Pattern)
+		}
+	}
+	if !rules[0].Match("AB123") || rules[0].Match("ab123") {
+		t.Errorf("regra %q aplicada errado", rules[0].Pattern)
+	}
+
+	// Regras montadas em código compilam na hora
+	if !(RegexRule{Pattern: `^\d+$`}).Match("42") || (RegexRule{Pattern: "["}).Match("[") {
+		t.Error("Match sem regex compilada")
+	}
+}
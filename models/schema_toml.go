@@ -0,0 +1,360 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Chaves TOML que podem ser escritas sem aspas
+var bareTOMLKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// parseTOMLNode lê um documento TOML como árvore de schemaNode.
+// A biblioteca não expõe a posição das chaves, então elas (e os valores depois do "=")
+// são localizadas no texto seguindo a ordem em que a biblioteca as encontrou (MetaData.Keys).
+func parseTOMLNode(data []byte) (*schemaNode, []SchemaIssue) {
+	var raw map[string]interface{}
+	md, err := toml.Decode(string(data), &raw)
+	if err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, []SchemaIssue{{
+				Line:    max(parseErr.Position.Line, 1),
+				Column:  max(parseErr.Position.Col, 1),
+				Message: "TOML inválido: " + parseErr.Message,
+			}}
+		}
+		return nil, []SchemaIssue{{Line: 1, Column: 1, Message: "TOML inválido: " + err.Error()}}
+	}
+
+	loc := newTOMLKeyLocator(data)
+	for _, key := range md.Keys() {
+		loc.record(key)
+	}
+
+	b := &tomlNodeBuilder{md: &md, loc: loc}
+	return b.build(raw, nil, 1, 1), nil
+}
+
+// tomlNodeBuilder monta a árvore a partir do valor decodificado e das posições das chaves
+type tomlNodeBuilder struct {
+	md  *toml.MetaData
+	loc *tomlKeyLocator
+}
+
+func (b *tomlNodeBuilder) build(value interface{}, path []string, line, column int) *schemaNode {
+	node := &schemaNode{line: line, column: column}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		node.kind = nodeObject
+		type entry struct {
+			key   *schemaNode
+			value *schemaNode
+		}
+		entries := []entry{}
+		for name, child := range v {
+			childPath := append(append([]string{}, path...), name)
+
+			if b.md.Type(childPath...) == "ArrayHash" {
+				// [[tabela]]: cada item tem o seu cabeçalho no arquivo
+				items, _ := child.([]map[string]interface{})
+				array := &schemaNode{kind: nodeArray, line: line, column: column}
+				for i, item := range items {
+					itemLine, itemColumn, _, _ := b.loc.next(childPath, line, column)
+					if i == 0 {
+						array.line, array.column = itemLine, itemColumn
+					}
+					array.values = append(array.values, b.build(item, childPath, itemLine, itemColumn))
+				}
+				key := &schemaNode{kind: nodeString, line: array.line, column: array.column, text: name}
+				entries = append(entries, entry{key, array})
+				continue
+			}
+
+			keyLine, keyColumn, valueLine, valueColumn := b.loc.next(childPath, line, column)
+			key := &schemaNode{kind: nodeString, line: keyLine, column: keyColumn, text: name}
+			entries = append(entries, entry{key, b.build(child, childPath, valueLine, valueColumn)})
+		}
+		// Mantém as chaves na ordem do arquivo
+		sort.SliceStable(entries, func(i, j int) bool {
+			a, c := entries[i].key, entries[j].key
+			return a.line < c.line || a.line == c.line && a.column < c.column
+		})
+		for _, e := range entries {
+			node.keys = append(node.keys, e.key)
+			node.values = append(node.values, e.value)
+		}
+	case []interface{}:
+		node.kind = nodeArray
+		for _, item := range v {
+			node.values = append(node.values, b.build(item, path, line, column))
+		}
+	case []map[string]interface{}:
+		node.kind = nodeArray
+		for _, item := range v {
+			node.values = append(node.values, b.build(item, path, line, column))
+		}
+	case string:
+		node.kind = nodeString
+		node.text = v
+	case int64:
+		node.kind = nodeNumber
+		node.text = strconv.FormatInt(v, 10)
+	case float64:
+		node.kind = nodeNumber
+		node.text = strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(node.text, ".") {
+			node.text += ".0"
+		}
+	case bool:
+		node.kind = nodeBool
+		node.text = strconv.FormatBool(v)
+	case time.Time:
+		node.kind = nodeString
+		node.text = v.Format(time.RFC3339)
+	default:
+		node.kind = nodeString
+		node.text = fmt.Sprint(v)
+	}
+	return node
+}
+
+// tomlKeyLocator guarda, para cada caminho de chave (ex.: "fields.name"), as posições
+// das suas ocorrências no arquivo, em ordem
+type tomlKeyLocator struct {
+	text      string // Arquivo com comentários e conteúdo de strings apagados
+	data      []byte
+	cursor    int
+	positions map[string][]tomlKeyPosition
+}
+
+// tomlKeyPosition é a posição de uma chave e a do seu valor. Em cabeçalhos ([tabela]) e
+// chaves pontuadas (a.b = 1), o valor da parte sem "=" é o próprio objeto e fica na chave.
+type tomlKeyPosition struct {
+	line, column           int
+	valueLine, valueColumn int
+}
+
+func newTOMLKeyLocator(data []byte) *tomlKeyLocator {
+	return &tomlKeyLocator{text: blankTOMLStrings(data), data: data, positions: map[string][]tomlKeyPosition{}}
+}
+
+// record procura a próxima ocorrência da chave a partir da anterior
+func (l *tomlKeyLocator) record(key toml.Key) {
+	if len(key) == 0 {
+		return
+	}
+	name := regexp.QuoteMeta(key[len(key)-1])
+	re := regexp.MustCompile(`(?m)(?:^|[\s{,\[.])(` + name + `)\s*[=\].]`)
+
+	m := re.FindStringSubmatchIndex(l.text[l.cursor:])
+	if m == nil {
+		return // Chave entre aspas: fica com a posição do objeto que a contém
+	}
+	start := l.cursor + m[2]
+	l.cursor = l.cursor + m[3]
+
+	pos := tomlKeyPosition{}
+	pos.line, pos.column = offsetPosition(l.data, start)
+	pos.valueLine, pos.valueColumn = pos.line, pos.column
+	if rest := strings.TrimLeft(l.text[l.cursor:], " \t"); strings.HasPrefix(rest, "=") {
+		value := len(l.text) - len(strings.TrimLeft(rest[1:], " \t"))
+		pos.valueLine, pos.valueColumn = offsetPosition(l.data, value)
+	}
+	path := strings.Join(key, ".")
+	l.positions[path] = append(l.positions[path], pos)
+}
+
+// next consome a próxima posição registrada para o caminho, retornando a da chave e a do
+// valor (ou as duas na posição de fallback)
+func (l *tomlKeyLocator) next(path []string, line, column int) (int, int, int, int) {
+	key := strings.Join(path, ".")
+	queue := l.positions[key]
+	if len(queue) == 0 {
+		return line, column, line, column
+	}
+	l.positions[key] = queue[1:]
+	return queue[0].line, queue[0].column, queue[0].valueLine, queue[0].valueColumn
+}
+
+// blankTOMLStrings troca comentários e conteúdo de strings por espaços (mantendo as
+// quebras de linha e os offsets), para que a busca de chaves não encontre texto de valores
+func blankTOMLStrings(data []byte) string {
+	out := []byte(string(data))
+	for i := 0; i < len(out); i++ {
+		switch out[i] {
+		case '#':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case '"', '\'':
+			quote := out[i]
+			delim := string(quote)
+			if i+2 < len(out) && out[i+1] == quote && out[i+2] == quote {
+				delim = strings.Repeat(delim, 3)
+			}
+			i += len(delim)
+			for i < len(out) && !strings.HasPrefix(string(out[i:]), delim) {
+				if quote == '"' && out[i] == '\\' && i+1 < len(out) {
+					out[i] = ' '
+					i++
+				}
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+				i++
+			}
+			i += len(delim) - 1
+		}
+	}
+	return string(out)
+}
+
+// encodeTOMLNode escreve a árvore como TOML: valores simples no topo, objetos como
+// [tabela], listas de objetos como [[tabela]] e o que estiver dentro deles como inline
+func encodeTOMLNode(root *schemaNode) ([]byte, error) {
+	if root.kind != nodeObject {
+		return nil, fmt.Errorf("o schema deve ser um objeto")
+	}
+
+	var sb strings.Builder
+	var tables, arrayTables []int
+	for i, value := range root.values {
+		switch {
+		case value.kind == nodeObject:
+			tables = append(tables, i)
+		case value.kind == nodeArray && len(value.values) > 0 && value.values[0].kind == nodeObject:
+			arrayTables = append(arrayTables, i)
+		default:
+			if err := writeTOMLEntry(&sb, root.keys[i].text, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, i := range tables {
+		fmt.Fprintf(&sb, "\n[%s]\n", tomlKey(root.keys[i].text))
+		if err := writeTOMLEntries(&sb, root.values[i]); err != nil {
+			return nil, err
+		}
+	}
+	for _, i := range arrayTables {
+		for _, item := range root.values[i].values {
+			fmt.Fprintf(&sb, "\n[[%s]]\n", tomlKey(root.keys[i].text))
+			if err := writeTOMLEntries(&sb, item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return []byte(sb.String()), nil
+}
+
+// writeTOMLEntries escreve as chaves de um objeto, uma por linha
+func writeTOMLEntries(sb *strings.Builder, obj *schemaNode) error {
+	if obj.kind != nodeObject {
+		return fmt.Errorf("linha %d: listas de tabelas só podem conter objetos", obj.line)
+	}
+	for i, key := range obj.keys {
+		if err := writeTOMLEntry(sb, key.text, obj.values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTOMLEntry escreve "chave = valor" (TOML não tem null: chaves nulas são omitidas)
+func writeTOMLEntry(sb *strings.Builder, key string, value *schemaNode) error {
+	if value.kind == nodeNull {
+		return nil
+	}
+	inline, err := tomlInline(value)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(sb, "%s = %s\n", tomlKey(key), inline)
+	return nil
+}
+
+// tomlInline formata um valor na sintaxe inline do TOML
+func tomlInline(n *schemaNode) (string, error) {
+	switch n.kind {
+	case nodeObject:
+		parts := []string{}
+		for i, key := range n.keys {
+			if n.values[i].kind == nodeNull {
+				continue
+			}
+			v, err := tomlInline(n.values[i])
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, tomlKey(key.text)+" = "+v)
+		}
+		if len(parts) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	case nodeArray:
+		parts := []string{}
+		for _, item := range n.values {
+			v, err := tomlInline(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, v)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case nodeString:
+		return tomlString(n.text), nil
+	case nodeNumber, nodeBool:
+		return n.text, nil
+	}
+	return "", fmt.Errorf("linha %d: TOML não representa null dentro de listas", n.line)
+}
+
+// tomlKey coloca a chave entre aspas quando ela não pode ser escrita sem
+func tomlKey(key string) string {
+	if bareTOMLKeyRegex.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlString formata uma string básica do TOML ("..." com os escapes da especificação)
+func tomlString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yaml.v3 informa a linha dos erros de sintaxe apenas no texto da mensagem
+var yamlErrorLineRegex = regexp.MustCompile(`line (\d+)`)
+
+// parseYAMLNode lê um documento YAML como árvore de schemaNode (yaml.Node já traz linha e coluna)
+func parseYAMLNode(data []byte) (*schemaNode, []SchemaIssue) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		line := 1
+		if m := yamlErrorLineRegex.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		return nil, []SchemaIssue{{Line: line, Column: 1, Message: "YAML inválido: " + err.Error()}}
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return nil, []SchemaIssue{{Line: 1, Column: 1, Message: "arquivo vazio"}}
+	}
+	return fromYAMLNode(doc.Content[0]), nil
+}
+
+// fromYAMLNode converte um yaml.Node (e seus filhos) para schemaNode
+func fromYAMLNode(y *yaml.Node) *schemaNode {
+	if y.Kind == yaml.AliasNode && y.Alias != nil {
+		y = y.Alias
+	}
+	node := &schemaNode{line: y.Line, column: y.Column, text: y.Value}
+
+	switch y.Kind {
+	case yaml.MappingNode:
+		node.kind = nodeObject
+		node.text = ""
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			key := y.Content[i]
+			node.keys = append(node.keys, &schemaNode{kind: nodeString, line: key.Line, column: key.Column, text: key.Value})
+			node.values = append(node.values, fromYAMLNode(y.Content[i+1]))
+		}
+	case yaml.SequenceNode:
+		node.kind = nodeArray
+		node.text = ""
+		for _, item := range y.Content {
+			node.values = append(node.values, fromYAMLNode(item))
+		}
+	default:
+		switch y.ShortTag() {
+		case "!!int", "!!float":
+			node.kind = nodeNumber
+		case "!!bool":
+			node.kind = nodeBool
+			node.text = strconv.FormatBool(y.Value == "true" || y.Value == "True" || y.Value == "TRUE")
+		case "!!null":
+			node.kind = nodeNull
+		default:
+			node.kind = nodeString
+		}
+	}
+	return node
+}
+
+// encodeYAMLNode escreve a árvore como YAML, mantendo a ordem das chaves
+func encodeYAMLNode(root *schemaNode) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(toYAMLNode(root)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toYAMLNode converte um schemaNode para yaml.Node; listas de valores simples
+// (ex.: enum, accept) ficam no estilo inline ["a", "b"]
+func toYAMLNode(n *schemaNode) *yaml.Node {
+	switch n.kind {
+	case nodeObject:
+		y := &yaml.Node{Kind: yaml.MappingNode}
+		for i, key := range n.keys {
+			y.Content = append(y.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.text},
+				toYAMLNode(n.values[i]),
+			)
+		}
+		return y
+	case nodeArray:
+		y := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, item := range n.values {
+			if item.kind == nodeObject || item.kind == nodeArray {
+				y.Style = 0
+			}
+			y.Content = append(y.Content, toYAMLNode(item))
+		}
+		return y
+	case nodeNumber:
+		tag := "!!int"
+		if !isIntegerText(n.text) {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: n.text}
+	case nodeBool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: n.text}
+	case nodeNull:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: n.text}
+}
+
+// isIntegerText indica se o número não tem parte decimal nem expoente
+func isIntegerText(text string) bool {
+	_, err := strconv.ParseInt(text, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,22 @@
+// Confere o avaliador do navegador (static/js/rules.js) contra os mesmos casos do Go
+// (rule_cases.json). Uso: node rules_agree.js <rules.js> <rule_cases.json>
+const fs = require('fs');
+const path = require('path');
+
+const rules = require(path.resolve(process.argv[2]));
+const cases = JSON.parse(fs.readFileSync(process.argv[3], 'utf8'));
+
+let failures = 0;
+for (const c of cases) {
+    let got, error = false;
+    try {
+        got = rules.parse(c.expr).eval(name => (name in c.values ? c.values[name] : null));
+    } catch (e) {
+        error = true;
+    }
+    if (Boolean(c.error) !== error || (!error && got !== c.want)) {
+        failures++;
+        console.log(`${JSON.stringify(c.expr)} ${JSON.stringify(c.values || {})}: esperava ${c.error ? 'erro' : c.want}, obteve ${error ? 'erro' : got}`);
+    }
+}
+process.exit(failures > 0 ? 1 : 0);
@@ -0,0 +1,37 @@
+package models
+
+import "regexp"
+
+// Cores aceitas no tema: hexadecimal (#rgb ou #rrggbb) ou nome CSS (ex.: teal)
+var colorRegex = regexp.MustCompile(`^(#[0-9A-Fa-f]{3}|#[0-9A-Fa-f]{6}|[A-Za-z]+)$`)
+
+// Theme personaliza a aparência da página. Pode vir do schema ("theme") e da
+// configuração (--theme-*); os valores do schema têm precedência.
+type Theme struct {
+	Title           string `json:"title"`            // Título da página (padrão: "Gerenciador: <tabela>")
+	Logo            string `json:"logo"`             // URL da imagem do cabeçalho (ex.: /static/logo.png)
+	PrimaryColor    string `json:"primary_color"`    // Cor dos botões e da página atual
+	BackgroundColor string `json:"background_color"` // Cor de fundo da página
+}
+
+// IsValidColor verifica se a cor pode ser usada no tema
+func IsValidColor(color string) bool {
+	return colorRegex.MatchString(color)
+}
+
+// WithDefaults completa os valores vazios do tema com os de defaults
+func (t Theme) WithDefaults(defaults Theme) Theme {
+	if t.Title == "" {
+		t.Title = defaults.Title
+	}
+	if t.Logo == "" {
+		t.Logo = defaults.Logo
+	}
+	if t.PrimaryColor == "" {
+		t.PrimaryColor = defaults.PrimaryColor
+	}
+	if t.BackgroundColor == "" {
+		t.BackgroundColor = defaults.BackgroundColor
+	}
+	return t
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldTypes lista os tipos de campo suportados (decimal aceita parâmetros: "decimal(12,4)")
+var FieldTypes = []string{
+	"int", "bigint", "float", "decimal", "string", "text", "bool",
+	"date", "datetime", "time", "json", "uuid", "ulid", "file", "image",
+}
+
+// Precisão e escala usadas por "decimal" sem parâmetros (mesmas do "float")
+const (
+	defaultDecimalPrecision = 10
+	defaultDecimalScale     = 2
+)
+
+// BaseType retorna o tipo do campo sem parâmetros (ex.: "decimal(12,4)" → "decimal")
+func (f Field) BaseType() string {
+	base, _, _ := strings.Cut(f.Type, "(")
+	return strings.TrimSpace(base)
+}
+
+// IsSortable indica se a lista pode ser ordenada pelo campo (textos longos, json e
+// arquivos não fazem sentido como ordenação)
+func (f Field) IsSortable() bool {
+	switch f.BaseType() {
+	case "text", "json", "file", "image":
+		return false
+	}
+	return true
+}
+
+// DecimalSpec retorna a precisão e a escala de um campo decimal(p,s)
+func (f Field) DecimalSpec() (precision, scale int, err error) {
+	precision, scale = defaultDecimalPrecision, defaultDecimalScale
+
+	_, params, ok := strings.Cut(f.Type, "(")
+	if !ok {
+		return precision, scale, nil
+	}
+	params, ok = strings.CutSuffix(strings.TrimSpace(params), ")")
+	if !ok {
+		return 0, 0, fmt.Errorf("tipo %q inválido: falta fechar parênteses", f.Type)
+	}
+
+	p, s, hasScale := strings.Cut(params, ",")
+	precision, err = strconv.Atoi(strings.TrimSpace(p))
+	if err != nil || precision < 1 || precision > 65 {
+		return 0, 0, fmt.Errorf("tipo %q inválido: precisão deve estar entre 1 e 65", f.Type)
+	}
+	scale = 0
+	if hasScale {
+		scale, err = strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || scale < 0 || scale > 30 || scale > precision {
+			return 0, 0, fmt.Errorf("tipo %q inválido: escala deve estar entre 0 e 30 e não pode exceder a precisão", f.Type)
+		}
+	}
+	return precision, scale, nil
+}
+
+// Tamanho padrão das colunas string (VARCHAR)
+const defaultStringLength = 255
+
+// MaxLength retorna o tamanho máximo de um campo string (propriedade "length" ou 255)
+func (f Field) MaxLength() int {
+	if f.Length > 0 {
+		return f.Length
+	}
+	return defaultStringLength
+}
+
+// IsUUID verifica se o valor está no formato de UUID (8-4-4-4-12 hexadecimais)
+func IsUUID(value string) bool {
+	return uuidRegex.MatchString(value)
+}
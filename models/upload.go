@@ -0,0 +1,93 @@
+package models
+
+import (
+	"mime"
+	"strings"
+)
+
+// Limites padrão de upload quando o campo não define max_size_mb
+const (
+	defaultFileMaxSizeMB  = 10
+	defaultImageMaxSizeMB = 5
+)
+
+// Tipos aceitos por campos image sem "accept" (SVG fica de fora: pode conter scripts)
+var defaultImageTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// IsUpload indica se o campo recebe um arquivo (tipos file e image)
+func (f Field) IsUpload() bool {
+	return f.Type == "file" || f.Type == "image"
+}
+
+// MaxUploadBytes retorna o tamanho máximo aceito para o arquivo do campo
+func (f Field) MaxUploadBytes() int64 {
+	sizeMB := f.MaxSizeMB
+	if sizeMB <= 0 {
+		sizeMB = defaultFileMaxSizeMB
+		if f.Type == "image" {
+			sizeMB = defaultImageMaxSizeMB
+		}
+	}
+	return int64(sizeMB) << 20
+}
+
+// AcceptedTypes retorna os tipos MIME aceitos (vazio = qualquer tipo)
+func (f Field) AcceptedTypes() []string {
+	if len(f.Accept) > 0 {
+		return f.Accept
+	}
+	if f.Type == "image" {
+		return defaultImageTypes
+	}
+	return nil
+}
+
+// AcceptAttr retorna o atributo accept do <input type="file">
+func (f Field) AcceptAttr() string {
+	return strings.Join(f.AcceptedTypes(), ",")
+}
+
+// AcceptsContentType verifica se o tipo MIME detectado é aceito pelo campo
+// (suporta curingas como "image/*")
+func (f Field) AcceptsContentType(contentType string) bool {
+	accepted := f.AcceptedTypes()
+	if len(accepted) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, a := range accepted {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// HasUploads indica se algum campo do schema recebe arquivos (formulário multipart)
+func (s *Schema) HasUploads() bool {
+	for _, field := range s.Fields {
+		if field.IsUpload() {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxRequestBytes limita o corpo das requisições de formulário: a soma dos uploads mais 1 MB para os demais campos
+func (s *Schema) MaxRequestBytes() int64 {
+	total := int64(1 << 20)
+	for _, field := range s.Fields {
+		if field.IsUpload() {
+			total += field.MaxUploadBytes()
+		}
+	}
+	return total
+}
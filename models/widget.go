@@ -0,0 +1,74 @@
+package models
+
+import "strings"
+
+// Widgets aceitos na propriedade "widget" de um campo
+var Widgets = []string{
+	"text", "textarea", "number", "date", "datetime-local", "time",
+	"checkbox", "select", "email", "tel", "password",
+}
+
+// InputWidget resolve o controle do formulário para o campo: a propriedade
+// "widget" tem precedência; senão o controle é deduzido do tipo, do enum e da validação
+func (f Field) InputWidget() string {
+	if f.Widget != "" {
+		return f.Widget
+	}
+	if f.IsUpload() {
+		return "file"
+	}
+	if len(f.Enum) > 0 {
+		return "select"
+	}
+	if f.Mask != "" { // IMask só funciona em inputs de texto
+		return "text"
+	}
+
+	switch f.BaseType() {
+	case "int", "bigint", "float", "decimal":
+		return "number"
+	case "text", "json":
+		return "textarea"
+	case "date":
+		return "date"
+	case "datetime":
+		return "datetime-local"
+	case "time":
+		return "time"
+	case "bool":
+		return "checkbox"
+	}
+
+	switch f.Validation.Type {
+	case "email":
+		return "email"
+	case "telefone":
+		return "tel"
+	}
+	return "text"
+}
+
+// InputStep retorna o atributo step de inputs numéricos (vazio para os demais)
+func (f Field) InputStep() string {
+	switch f.BaseType() {
+	case "int", "bigint":
+		return "1"
+	case "float":
+		return "0.01" // DECIMAL(10, 2)
+	case "decimal":
+		_, scale, err := f.DecimalSpec()
+		if err != nil || scale == 0 {
+			return "1"
+		}
+		return "0." + strings.Repeat("0", scale-1) + "1"
+	}
+	return ""
+}
+
+// DisplayLabel retorna o rótulo do campo, ou o nome quando não há label
+func (f Field) DisplayLabel() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return f.Name
+}
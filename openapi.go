@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-crud-generator/config"
+	"go-crud-generator/controllers"
+	"go-crud-generator/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runOpenAPI implementa "crud-app openapi": gera a especificação OpenAPI das rotas do
+// CRUD a partir do schema (não conecta ao banco)
+func runOpenAPI(args []string) int {
+	fs := newCommandFlags("openapi", "[--out openapi.json] [--json-schema arquivo]",
+		"Gera a especificação OpenAPI 3 das rotas do CRUD para o schema.\n"+
+			"O formato é escolhido pela extensão de --out (.json, .yaml ou .yml).")
+	out := fs.String("out", "", "Arquivo de saída (padrão: JSON na saída padrão)")
+	cfg, code := loadCommandConfig(fs, args, config.WithSchema)
+	if cfg == nil {
+		return code
+	}
+
+	schema, err := models.LoadSchema(cfg.JSONSchemaPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao carregar schema: %v\n", err)
+		return 1
+	}
+
+	spec := controllers.OpenAPISpec(schema)
+	var data []byte
+	switch strings.ToLower(filepath.Ext(*out)) {
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(spec)
+	default:
+		data, err = json.MarshalIndent(spec, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	if *out == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ Especificação OpenAPI gravada em %s\n", *out)
+	return 0
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"html/template"
+	"log"
+
+	"go-crud-generator/config"
+	"go-crud-generator/controllers"
+	"go-crud-generator/models"
+)
+
+// reloadApp recarrega schema e templates sem reiniciar o servidor. A nova versão só
+// entra no ar se passar por todas as etapas; caso contrário a atual continua atendendo
+// e o motivo é registrado no log.
+func reloadApp(cfg *config.Config, db *sql.DB, controller *controllers.CRUDController) {
+	log.Println("🔄 Alteração detectada, recarregando schema e templates...")
+
+	schema, tmpl, err := loadSchemaAndTemplates(cfg)
+	if err != nil {
+		log.Printf("❌ Recarga rejeitada: %v", err)
+		return
+	}
+
+	// Dry-run: o banco valida a definição completa antes de qualquer alteração real
+	plan, err := models.DryRunMigration(db, schema)
+	if err != nil {
+		log.Printf("❌ Recarga rejeitada no dry-run da migração: %v", err)
+		return
+	}
+	for _, step := range plan {
+		log.Printf("   migração: %s", step.Up)
+	}
+
+	// Sem --auto-migrate o banco não é alterado: a nova versão exige a tabela já migrada
+	if len(plan) > 0 && !cfg.AutoMigrate {
+		log.Printf("❌ Recarga rejeitada: a tabela '%s' precisa de migração (rode ./crud-app migrate ou use --auto-migrate)", schema.TableName)
+		return
+	}
+
+	if _, err := models.AutoMigrate(db, schema); err != nil {
+		log.Printf("❌ Recarga rejeitada: %v", err)
+		return
+	}
+
+	controller.Reload(models.NewDynamicRepository(db, schema), schema, tmpl)
+	log.Printf("✅ Schema recarregado (tabela '%s', %d campos).", schema.TableName, len(schema.Fields))
+}
+
+// loadSchemaAndTemplates carrega o schema (LoadSchema já o valida) e parseia os templates, checando
+// que eles renderizam com o schema
+func loadSchemaAndTemplates(cfg *config.Config) (*models.Schema, *template.Template, error) {
+	schema, err := models.LoadSchema(cfg.JSONSchemaPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tmpl, err := controllers.ParseTemplates(templatesFS(cfg))
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao parsear template: %w", err)
+	}
+	if err := controllers.CheckTemplate(tmpl, schema); err != nil {
+		return nil, nil, fmt.Errorf("erro ao renderizar template: %w", err)
+	}
+
+	return schema, tmpl, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"go-crud-generator/config"
+	"go-crud-generator/models"
+)
+
+// runValidateSchema implementa "crud-app validate-schema [arquivo]": valida o schema sem
+// conectar ao banco, lista os problemas com linha e coluna e retorna o código de saída
+// (0 válido, 1 inválido, 2 uso incorreto)
+func runValidateSchema(args []string) int {
+	fs := newCommandFlags("validate-schema", "[--json-schema] arquivo",
+		"Valida o schema e lista cada problema como arquivo:linha:coluna: mensagem.")
+	cfg, code := loadCommandConfig(fs, args, config.WithSchema|config.NoValidation)
+	if cfg == nil {
+		return code
+	}
+	path := cfg.JSONSchemaPath
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		fs.Usage()
+		return 2
+	}
+
+	schema, err := models.LoadSchema(path)
+	var schemaErr *models.SchemaError
+	if errors.As(err, &schemaErr) {
+		for _, issue := range schemaErr.Issues {
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: %s\n", schemaErr.Path, issue.Line, issue.Column, issue.Message)
+		}
+		fmt.Fprintf(os.Stderr, "❌ %d problema(s) encontrado(s)\n", len(schemaErr.Issues))
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("✅ %s válido: tabela '%s', %d campo(s)\n", path, schema.TableName, len(schema.Fields))
+	return 0
+}
+
+// runConvertSchema implementa "crud-app convert-schema entrada saida": converte o schema
+// entre JSON, YAML e TOML (formatos escolhidos pela extensão), validando a entrada antes
+func runConvertSchema(args []string) int {
+	fs := newCommandFlags("convert-schema", "entrada saida",
+		"Converte o schema entre .json, .yaml/.yml e .toml (pela extensão dos arquivos).\n"+
+			"Ex.: ./crud-app convert-schema schema.json schema.yaml")
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	src, dst := fs.Arg(0), fs.Arg(1)
+
+	if err := models.ConvertSchemaFile(src, dst); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("✅ %s convertido para %s\n", src, dst)
+	return 0
+}
+
+// runIntrospect implementa "crud-app introspect --table nome": lê a tabela no banco e
+// gera um schema para ela (formato pela extensão de --out)
+func runIntrospect(args []string) int {
+	fs := newCommandFlags("introspect", "--table nome [--out schema.json] [--force] [opções do banco]",
+		"Gera o schema de uma tabela existente a partir do information_schema.")
+	table := fs.String("table", "", "Tabela a ler (obrigatório)")
+	out := fs.String("out", "schema.json", "Arquivo de schema a gerar (.json, .yaml ou .toml)")
+	force := fs.Bool("force", false, "Sobrescreve o arquivo de saída se ele existir")
+
+	cfg, code := loadCommandConfig(fs, args, config.WithDB)
+	if cfg == nil {
+		return code
+	}
+	if *table == "" {
+		fs.Usage()
+		return 2
+	}
+	if _, err := os.Stat(*out); err == nil && !*force {
+		fmt.Fprintf(os.Stderr, "❌ %s já existe (use --force para sobrescrever)\n", *out)
+		return 1
+	}
+
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao conectar ao banco de dados: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	schema, warnings, err := models.IntrospectTable(db, *table)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	if err := models.WriteSchemaFile(*out, schema); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "⚠️  %s\n", warning)
+	}
+	fmt.Printf("✅ Schema da tabela '%s' gravado em %s (%d campo(s))\n", *table, *out, len(schema.Fields))
+
+	// O schema gerado passa pela mesma validação da inicialização (ex.: nomes reservados)
+	if _, err := models.LoadSchema(*out); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Revise o schema gerado antes de usá-lo:\n%v\n", err)
+	}
+	return 0
+}
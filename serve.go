@@ -0,0 +1,151 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"go-crud-generator/config"
+	"go-crud-generator/controllers"
+	"go-crud-generator/models"
+)
+
+// Intervalo de verificação dos arquivos observados com --watch
+const watchInterval = time.Second
+
+// runServe implementa "crud-app serve": carrega o schema, confere a tabela no banco e
+// sobe o servidor. A migração só é aplicada com --auto-migrate; sem ele o servidor não
+// sobe enquanto houver alterações pendentes (use "crud-app migrate").
+func runServe(args []string) int {
+	fs := newCommandFlags("serve", "[opções]",
+		"Sobe o servidor web do CRUD para a tabela descrita no schema.\n"+
+			"Sem --auto-migrate, a tabela já deve estar migrada (./crud-app migrate).")
+	cfg, code := loadCommandConfig(fs, args, config.WithDB|config.WithSchema|config.WithServer)
+	if cfg == nil {
+		return code
+	}
+
+	// Exibir configuração carregada
+	log.Println("=== Configuração Carregada ===")
+	if cfg.Profile != "" {
+		log.Printf("Config File: %s (perfil %s)", cfg.ConfigFile, cfg.Profile)
+	} else if cfg.ConfigFile != "" {
+		log.Printf("Config File: %s", cfg.ConfigFile)
+	}
+	log.Printf("DB Host:     %s", cfg.DBHost)
+	log.Printf("DB Port:     %s", cfg.DBPort)
+	log.Printf("DB User:     %s", cfg.DBUser)
+	log.Printf("DB Password: %s", maskPassword(cfg.DBPassword))
+	log.Printf("DB Name:     %s", cfg.DBName)
+	log.Printf("App Port:    %s", cfg.Port)
+	log.Printf("JSON Schema: %s", cfg.JSONSchemaPath)
+	log.Printf("Storage:     %s", describeStorage(cfg))
+	if cfg.Pagination == "keyset" {
+		log.Printf("Paginação:   keyset (contagem: %s)", cfg.ListCount)
+	}
+	if cfg.TemplatesDir != "" {
+		log.Printf("Templates:   %s", cfg.TemplatesDir)
+	}
+	if cfg.StaticDir != "" {
+		log.Printf("Static:      %s", cfg.StaticDir)
+	}
+	log.Println("==============================")
+
+	// 1. Carregar Schema JSON (e o template que o renderiza)
+	schema, tmpl, err := loadSchemaAndTemplates(cfg)
+	if err != nil {
+		log.Fatalf("❌ Erro ao carregar schema JSON: %v", err)
+	}
+	log.Println("✅ Schema JSON carregado com sucesso.")
+
+	// 2. Conectar ao Banco de Dados
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		log.Fatalf("❌ Erro ao conectar ao banco de dados: %v", err)
+	}
+	defer db.Close()
+	log.Println("✅ Conexão com MySQL estabelecida.")
+
+	// 3. Migração: aplicada só com --auto-migrate; sem ele, exige a tabela em dia
+	if cfg.AutoMigrate {
+		steps, err := models.AutoMigrate(db, schema)
+		if err != nil {
+			log.Fatalf("❌ Erro ao executar migração automática: %v", err)
+		}
+		for _, step := range steps {
+			log.Printf("   migração: %s", step.Up)
+		}
+		log.Printf("✅ Tabela '%s' garantida.", schema.TableName)
+	} else {
+		plan, err := models.PlanMigration(db, schema)
+		if err != nil {
+			log.Fatalf("❌ Erro ao verificar a tabela: %v", err)
+		}
+		if len(plan) > 0 {
+			log.Printf("❌ A tabela '%s' não está de acordo com o schema (%d alteração(ões) pendente(s)):", schema.TableName, len(plan))
+			for _, step := range plan {
+				log.Printf("   %s", step.Up)
+			}
+			log.Fatal("   Rode ./crud-app migrate ou inicie com --auto-migrate.")
+		}
+		log.Printf("✅ Tabela '%s' de acordo com o schema.", schema.TableName)
+	}
+
+	// 4. Inicializar Camadas
+	repo := models.NewDynamicRepository(db, schema)
+
+	// Storage dos uploads (campos file/image)
+	store, err := config.InitStorage(cfg)
+	if err != nil {
+		log.Fatalf("❌ Erro ao inicializar storage de arquivos: %v", err)
+	}
+
+	// 5. Configurar Controllers e Rotas
+	crudController := controllers.NewCRUDController(repo, schema, tmpl, store, configTheme(cfg), configList(cfg))
+
+	mux := http.NewServeMux()
+	crudController.RegisterRoutes(mux)
+
+	// Servir arquivos estáticos (embutidos ou de --static-dir)
+	static := http.FileServer(http.FS(staticFS(cfg)))
+	mux.Handle("/static/", http.StripPrefix("/static/", static))
+
+	// Recarga a quente do schema e dos templates
+	if cfg.Watch {
+		go config.WatchFiles(func() []string { return watchedFiles(cfg) }, watchInterval, nil, func() {
+			reloadApp(cfg, db, crudController)
+		})
+		watched := cfg.JSONSchemaPath
+		if cfg.TemplatesDir != "" {
+			watched += ", " + filepath.Join(cfg.TemplatesDir, "*.html")
+		}
+		log.Printf("👀 Observando alterações em %s", watched)
+	}
+
+	// 6. Iniciar Servidor
+	log.Printf("🚀 Servidor iniciado na porta :%s", cfg.Port)
+	log.Printf("📍 Acesse: http://localhost:%s", cfg.Port)
+	if err := http.ListenAndServe(":"+cfg.Port, mux); err != nil {
+		log.Fatalf("❌ Erro ao iniciar servidor: %v", err)
+	}
+	return 0
+}
+
+// configTheme é o tema definido na configuração (--theme-*), usado onde o schema não define o seu
+func configTheme(cfg *config.Config) models.Theme {
+	return models.Theme{
+		Title:           cfg.ThemeTitle,
+		Logo:            cfg.ThemeLogo,
+		PrimaryColor:    cfg.ThemePrimaryColor,
+		BackgroundColor: cfg.ThemeBackgroundColor,
+	}
+}
+
+// configList é a navegação da lista escolhida na configuração (--pagination, --list-count)
+func configList(cfg *config.Config) controllers.ListConfig {
+	return controllers.ListConfig{
+		Keyset: cfg.Pagination == "keyset",
+		Count:  models.CountMode(cfg.ListCount),
+	}
+}
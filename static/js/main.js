@@ -14,11 +14,14 @@ document.addEventListener('DOMContentLoaded', () => {
     const formSubmitBtn = document.getElementById('form-submit-btn');
     const formCancelBtn = document.getElementById('form-cancel-btn');
     const formIdField = document.getElementById('form-id-field');
+    const formVersionField = document.getElementById('form-version-field'); // Lock otimista (opcional)
     const formCard = document.getElementById('form-card');
-    const formInputs = form.querySelectorAll('input[name]');
+    // Inputs, textareas e selects do formulário (exceto o "0" oculto que acompanha cada checkbox,
+    // o checkbox "Remover" dos campos de arquivo e o estado da lista)
+    const formInputs = form.querySelectorAll('input[name]:not([data-bool-default]):not([data-remove-file]):not([data-list-state]), textarea[name], select[name]');
 
     // --- Estado do Formulário ---
-    const originalFormAction = form.action;
+    const originalFormAction = '/create';
     const originalFormTitle = formTitle.innerText;
     const originalSubmitText = formSubmitBtn.innerText;
     let inputMasks = []; // Armazena instâncias do IMask
@@ -26,9 +29,10 @@ document.addEventListener('DOMContentLoaded', () => {
     // --- 1. INICIALIZAÇÃO ---
 
     /**
-     * Inicializa as máscaras em todos os inputs com 'data-mask'
+     * Monta as opções do IMask para o padrão de máscara do schema
+     * @param {string} maskPattern - ex: "999.999.999-99"
      */
-     const initMasks = () => {
+    const maskOptionsFor = (maskPattern) => {
        const customDefinitions = {
             // '9': Dígito (já definido implicitamente na maioria das vezes,
             // mas vamos garantir que ele seja mapeado para 0-9)
@@ -47,41 +51,48 @@ document.addEventListener('DOMContentLoaded', () => {
                 lazy: false
             }
         };
+
+        // --- INÍCIO DA CORREÇÃO ---
+        // IMask.js usa '0' como placeholder de dígito, não '9'.
+        // Vamos converter nosso padrão "9" para o padrão "0" do IMask.
+        const imaskPattern = maskPattern.replace(/9/g, '0');
+        // --- FIM DA CORREÇÃO ---
+
+        let maskOptions = {
+            mask: imaskPattern, // <-- USA A VARIÁVEL CORRIGIDA
+            lazy: false, // Preenche a máscara à medida que o usuário digita
+            definitions: customDefinitions
+        };
+
+        // Lógica especial para máscaras dinâmicas (ex: Telefone)
+        // O schema original é "(99) 99999-9999" (com 9)
+        // O IMask precisa de "(00) 00000-0000" (com 0)
+        if (maskPattern.includes('(99) 9')) {
+            maskOptions.mask = [
+                {
+                    mask: '(00) 0000-0000', // Convertido para '0'
+                    lazy: false
+                },
+                {
+                    mask: '(00) 00000-0000', // Convertido para '0'
+                    lazy: false
+                }
+            ];
+        }
+        return maskOptions;
+    };
+
+    /**
+     * Inicializa as máscaras em todos os inputs com 'data-mask'
+     */
+     const initMasks = () => {
         inputMasks = []; // Limpa máscaras antigas
         formInputs.forEach(input => {
             const maskPattern = input.dataset.mask; // ex: "999.999.999-99"
             if (!maskPattern) return;
 
-            // --- INÍCIO DA CORREÇÃO ---
-            // IMask.js usa '0' como placeholder de dígito, não '9'.
-            // Vamos converter nosso padrão "9" para o padrão "0" do IMask.
-            const imaskPattern = maskPattern.replace(/9/g, '0');
-            // --- FIM DA CORREÇÃO ---
-
-            let maskOptions = {
-                mask: imaskPattern, // <-- USA A VARIÁVEL CORRIGIDA
-                lazy: false, // Preenche a máscara à medida que o usuário digita
-                definitions: customDefinitions
-            };
-
-            // Lógica especial para máscaras dinâmicas (ex: Telefone)
-            // O schema original é "(99) 99999-9999" (com 9)
-            // O IMask precisa de "(00) 00000-0000" (com 0)
-            if (maskPattern.includes('(99) 9')) {
-                maskOptions.mask = [
-                    {
-                        mask: '(00) 0000-0000', // Convertido para '0'
-                        lazy: false
-                    },
-                    {
-                        mask: '(00) 00000-0000', // Convertido para '0'
-                        lazy: false
-                    }
-                ];
-            }
-
             // Cria a instância do IMask e armazena
-            const maskInstance = IMask(input, maskOptions);
+            const maskInstance = IMask(input, maskOptionsFor(maskPattern));
             inputMasks.push(maskInstance);
         });
     };
@@ -91,9 +102,9 @@ document.addEventListener('DOMContentLoaded', () => {
      */
     const initValidation = () => {
         formInputs.forEach(input => {
-            // Valida quando o usuário *sai* do campo
+            // Valida quando o usuário *sai* do campo (e, se passar, confere no banco)
             input.addEventListener('blur', (e) => {
-                validateField(e.target);
+                if (validateField(e.target)) scheduleRemoteCheck(e.target);
             });
 
             // Limpa o erro assim que o usuário começa a corrigir
@@ -111,61 +122,195 @@ document.addEventListener('DOMContentLoaded', () => {
      * @returns {boolean} - True se for válido, False se for inválido
      */
     const validateField = (input) => {
-        const value = input.value;
-        const type = input.dataset.validateType;
         const isRequired = input.hasAttribute('required');
 
-        // 1. Validação de Obrigatório
-        if (isRequired && value.trim() === '') {
-            showError(input, 'Campo obrigatório');
-            return false;
-        }
-
-        // 2. Validações de Tipo (CPF, Email, etc.)
-        if (value.trim() !== '') { // Só valida o formato se não estiver vazio
-            switch (type) {
-                case 'cpf':
-                    if (!isValidCPF(value, isRequired)) {
-                        showError(input, 'CPF inválido');
-                        return false;
-                    }
-                    break;
-                case 'email':
-                    if (!isValidEmail(value, isRequired)) {
-                        showError(input, 'Email inválido');
-                        return false;
-                    }
-                    break;
-                case 'cnpj':
-                    if (!isValidCNPJ(value, isRequired)) {
-                        showError(input, 'CNPJ inválido');
-                        return false;
-                    }
-                    break;
-                case 'telefone':
-                    if (!isValidTelefone(value, isRequired)) {
-                        showError(input, 'Telefone inválido');
-                        return false;
-                    }
-                    break;
-                case 'cep':
-                    if (!isValidCEP(value, isRequired)) {
-                        showError(input, 'CEP inválido');
-                        return false;
-                    }
-                    break;
-                // Adicione casos para 'cnpj', 'telefone', 'cep' aqui
+        // Arquivos: na edição o arquivo atual satisfaz o obrigatório; o tamanho é checado antes do envio
+        if (input.type === 'file') {
+            const file = input.files[0];
+            if (!file) {
+                if (isRequired && input.dataset.hasCurrent !== '1') {
+                    showError(input, 'Campo obrigatório');
+                    return false;
+                }
+            } else if (input.dataset.maxSize && file.size > Number(input.dataset.maxSize)) {
+                showError(input, `Arquivo maior que ${Math.round(Number(input.dataset.maxSize) / 1048576)} MB`);
+                return false;
             }
+            clearError(input);
+            return true;
         }
 
-        // 3. Validações de Regex (se passadas do backend)
-        // (Esta parte pode ser adicionada depois, lendo data-regex-pattern)
+        const message = fieldError(input);
+        if (message) {
+            showError(input, message);
+            return false;
+        }
 
         // Se passou por tudo, está válido
         clearError(input);
         return true;
     };
 
+    // Manifesto de validação do schema (/api/validation): as mesmas regras e mensagens do
+    // servidor. Enquanto não carrega (ou se falhar), só o obrigatório é checado aqui.
+    let manifest = { fields: [], rules: [], messages: {} };
+    fetch('/api/validation')
+        .then(response => response.ok ? response.json() : Promise.reject(new Error(`HTTP ${response.status}`)))
+        .then(data => { manifest = data; })
+        .catch(error => console.warn('Manifesto de validação indisponível; o servidor valida no envio.', error));
+
+    /**
+     * Mensagem de erro do manifesto (%d é o limite da regra)
+     * @param {string} key - ex: 'required', 'cpf', 'max_length'
+     * @param {number} [limit]
+     */
+    const manifestMessage = (key, limit) => (manifest.messages[key] || 'Valor inválido').replace('%d', limit);
+
+    /**
+     * Converte um padrão de regex_rules (sintaxe do Go) para RegExp; (?i) no início vira a
+     * flag i. Padrões sem equivalente em JavaScript ficam para o servidor (retorna null).
+     * @param {string} pattern
+     */
+    const toRegExp = (pattern) => {
+        let flags = 'u';
+        if (pattern.startsWith('(?i)')) {
+            pattern = pattern.slice(4);
+            flags += 'i';
+        }
+        try {
+            return new RegExp(pattern, flags);
+        } catch (e) {
+            try {
+                return new RegExp(pattern, flags.replace('u', ''));
+            } catch (e2) {
+                return null;
+            }
+        }
+    };
+
+    /**
+     * Verifica o valor de um campo (exceto arquivos) contra o manifesto, na ordem de
+     * validators.ValidateField: obrigatório, validação padrão, enum, regex_rules e tipo
+     * @param {HTMLInputElement} input
+     * @returns {string} - Mensagem de erro, ou '' se o valor for válido
+     */
+    const fieldError = (input) => {
+        const value = input.value;
+        const spec = manifest.fields.find(field => field.name === input.name);
+        if (spec && spec.type === 'bool') return '';
+
+        // 1. Validação de Obrigatório
+        if (value === '') {
+            const isRequired = spec ? spec.required : input.hasAttribute('required');
+            return isRequired ? (manifest.messages.required || 'Campo obrigatório') : '';
+        }
+        if (!spec) return '';
+
+        // 2. Validações Padrão (CPF, Email, etc.)
+        if (!matchesStandard(spec.validation, value)) {
+            return manifestMessage(spec.validation);
+        }
+
+        // 3. Enum e regex_rules (para no primeiro erro, como no servidor)
+        if (spec.enum && !spec.enum.includes(value)) {
+            return manifestMessage('enum');
+        }
+        for (const rule of spec.regex_rules || []) {
+            const regex = toRegExp(rule.pattern);
+            if (regex && !regex.test(value)) return rule.message;
+        }
+
+        // 4. Tipo (datas e horas já vêm no formato dos inputs nativos)
+        return typeError(spec, value);
+    };
+
+    /**
+     * Confere o valor contra o tipo do campo, como a conversão de validators.ValidateField
+     * @param {object} spec - Campo do manifesto
+     * @param {string} value
+     * @returns {string} - Mensagem de erro, ou ''
+     */
+    const typeError = (spec, value) => {
+        switch (spec.type) {
+            case 'int':
+            case 'bigint': {
+                if (!/^[+-]?\d+$/.test(value)) return manifestMessage('integer');
+                // Intervalo da coluna (INT: 32 bits, BIGINT: 64 bits), conferido com BigInt
+                const bits = spec.type === 'int' ? 31n : 63n;
+                const number = BigInt(value);
+                return number < -(2n ** bits) || number >= 2n ** bits ? manifestMessage(spec.type + '_range') : '';
+            }
+            case 'float':
+                return /^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$/.test(value) ? '' : manifestMessage('number');
+            case 'decimal': {
+                // Aceita vírgula como separador decimal (pt-BR)
+                const number = value.includes('.') ? value : value.replace(',', '.');
+                if (!/^[+-]?\d*\.?\d+$|^[+-]?\d+\.$/.test(number)) return manifestMessage('number');
+                const [intPart, fracPart = ''] = number.replace(/^[+-]/, '').split('.');
+                if (fracPart.length > spec.scale) return manifestMessage('scale', spec.scale);
+                if (intPart.replace(/^0+/, '').length > spec.precision - spec.scale) {
+                    return manifestMessage('precision', spec.precision - spec.scale);
+                }
+                return '';
+            }
+            case 'uuid':
+                return /^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$/.test(value) ? '' : manifestMessage('uuid');
+            case 'json':
+                try {
+                    JSON.parse(value);
+                    return '';
+                } catch (e) {
+                    return manifestMessage('json');
+                }
+            case 'string': {
+                // O limite vale para o valor salvo, sem os separadores da máscara
+                const saved = spec.mask ? value.replace(/[.\-()/ _]/g, '') : value;
+                return [...saved].length > spec.max_length ? manifestMessage('max_length', spec.max_length) : '';
+            }
+        }
+        return '';
+    };
+
+    // Conferências que só o servidor faz (valor já cadastrado, chave estrangeira inexistente),
+    // para os campos marcados com "remote" no manifesto
+    const REMOTE_CHECK_DELAY = 300; // ms
+    const remoteChecks = {}; // Por campo: { timer, controller }
+
+    /**
+     * Agenda a validação do campo em /api/validate; saídas seguidas do mesmo campo geram uma
+     * só requisição, e uma resposta que chega depois de o valor mudar é descartada
+     * @param {HTMLInputElement} input
+     */
+    const scheduleRemoteCheck = (input) => {
+        const spec = manifest.fields.find(field => field.name === input.name);
+        if (!spec || !spec.remote || input.value === '') return;
+
+        const pending = remoteChecks[input.name] || {};
+        clearTimeout(pending.timer);
+        if (pending.controller) pending.controller.abort();
+
+        const value = input.value;
+        const controller = new AbortController();
+        const timer = setTimeout(async () => {
+            // Na edição, o próprio registro não conta como valor repetido
+            const url = formIdField.value ? `/api/validate?${formIdField.value}` : '/api/validate';
+            try {
+                const response = await fetch(url, {
+                    method: 'POST',
+                    body: new URLSearchParams({ [input.name]: value }),
+                    signal: controller.signal
+                });
+                if (!response.ok) return; // O envio do formulário valida de novo
+                const result = await response.json();
+                const message = result.errors && result.errors[input.name];
+                if (message && input.value === value) showError(input, message);
+            } catch (error) {
+                if (error.name !== 'AbortError') console.warn('Falha ao validar no servidor:', error);
+            }
+        }, REMOTE_CHECK_DELAY);
+        remoteChecks[input.name] = { timer, controller };
+    };
+
     /**
      * Exibe a mensagem de erro para um campo
      * @param {HTMLInputElement} input
@@ -212,24 +357,85 @@ document.addEventListener('DOMContentLoaded', () => {
      */
     const validateForm = () => {
         let isFormValid = true;
+        const invalidFields = new Set();
         formInputs.forEach(input => {
             // Se 'validateField' retornar false, o formulário é inválido
             if (!validateField(input)) {
                 isFormValid = false;
+                invalidFields.add(input.name);
+            }
+        });
+        return checkRules(invalidFields) && isFormValid;
+    };
+
+    // --- 2.1. REGRAS ENTRE CAMPOS ("rules" do schema) ---
+    // A linguagem fica em rules.js, conferida contra models/rules.go pelos mesmos casos de teste
+
+    const { parse: parseRule, truthy } = window.CrudRules;
+
+    const formErrorJS = document.getElementById('error-js-_form');
+
+    /**
+     * Lê o valor de um campo como as regras o veem: null se vazio, boolean para checkbox e
+     * texto sem os separadores da máscara (como em models.MaskSeparators) para os demais
+     * @param {string} name
+     */
+    const ruleFieldValue = (name) => {
+        const inputs = Array.from(formInputs).filter(el => el.name === name);
+        if (inputs.length === 0) return null;
+        if (inputs[0].type === 'checkbox') return inputs[0].checked;
+        const input = inputs.find(el => el.type !== 'radio' || el.checked);
+        let value = input ? input.value : '';
+        if (input && input.dataset.mask) value = value.replace(/[.\-()/ _]/g, '');
+        return value === '' ? null : value;
+    };
+
+    /**
+     * Avalia as regras do schema e exibe a mensagem nos campos de cada regra violada.
+     * Regras que citam um campo já com erro ficam de fora, como no servidor.
+     * @param {Set<string>} invalidFields - Campos que falharam na validação individual
+     * @returns {boolean} - True se todas as regras forem satisfeitas
+     */
+    const checkRules = (invalidFields) => {
+        if (formErrorJS) formErrorJS.classList.add('hidden');
+        let valid = true;
+        manifest.rules.forEach(rule => {
+            let when, check;
+            try {
+                when = rule.when ? parseRule(rule.when) : null;
+                check = parseRule(rule.check);
+            } catch (err) {
+                console.error(err.message);
+                return;
+            }
+            const names = [...(when ? when.idents : []), ...check.idents, ...(rule.fields || [])];
+            if (names.some(name => invalidFields.has(name))) return;
+            if (when && !truthy(when.eval(ruleFieldValue))) return;
+            if (truthy(check.eval(ruleFieldValue))) return;
+
+            valid = false;
+            (rule.fields || []).forEach(name => {
+                invalidFields.add(name);
+                const input = Array.from(formInputs).find(el => el.name === name);
+                if (input) showError(input, rule.message);
+            });
+            if (!(rule.fields || []).length && formErrorJS) {
+                formErrorJS.innerText = rule.message;
+                formErrorJS.classList.remove('hidden');
             }
         });
-        return isFormValid;
+        return valid;
     };
 
     // --- 3. LÓGICA DE EDIÇÃO / CRIAÇÃO ---
 
     /**
      * Prepara o formulário para edição (chamado pelo HTML)
-     * @param {string} id
+     * @param {string} key - Chave primária como query string (ex.: "id=1" ou "pedido_id=1&produto_id=2")
      */
-    window.startEdit = async (id) => {
+    window.startEdit = async (key) => {
         try {
-            const response = await fetch(`/get?id=${id}`);
+            const response = await fetch(`/get?${key}`);
             if (!response.ok) throw new Error('Falha ao carregar dados');
 
             const data = await response.json();
@@ -239,13 +445,28 @@ document.addEventListener('DOMContentLoaded', () => {
 
             // Popula os campos
             formInputs.forEach(input => {
-                if (data[input.name]) {
+                if (data[input.name] !== undefined && data[input.name] !== null) {
                     let value = data[input.name];
 
+                    // Arquivo: não dá para preencher um input file; mostra o link do atual
+                    if (input.type === 'file') {
+                        showCurrentFile(input, value);
+                        return;
+                    }
+
+                    // Checkbox (bool): marca conforme o valor
+                    if (input.type === 'checkbox') {
+                        input.checked = value === true || value === '1' || value === 'true';
+                        return;
+                    }
+
                     // Trata datas
                     if (input.type === 'date' && value) {
                         value = value.split('T')[0]; // Formato AAAA-MM-DD
                     }
+                    if (input.type === 'datetime-local' && value) {
+                        value = value.replace(' ', 'T').substring(0, 16); // Formato AAAA-MM-DDTHH:MM
+                    }
 
                     input.value = value;
 
@@ -257,12 +478,12 @@ document.addEventListener('DOMContentLoaded', () => {
                 }
             });
 
-            // Atualiza UI do formulário para modo "Edição"
-            formIdField.value = id;
-            form.action = `/update?id=${id}`;
-            formTitle.innerText = `Editando Registro #${id}`;
-            formSubmitBtn.innerText = 'Atualizar';
-            formCancelBtn.style.display = 'inline-block';
+            // Guarda a versão carregada para o servidor detectar edições concorrentes
+            if (formVersionField) {
+                formVersionField.value = data[formVersionField.name] ?? '';
+            }
+
+            setEditMode(key);
 
             formCard.scrollIntoView({ behavior: 'smooth' });
 
@@ -272,6 +493,39 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     };
 
+    /**
+     * Exibe (ou oculta, se key for vazia) o link do arquivo atual de um campo file/image
+     * @param {HTMLInputElement} input
+     * @param {string} key - Chave do arquivo no storage
+     */
+    const showCurrentFile = (input, key) => {
+        const container = document.getElementById(`current-file-${input.name}`);
+        const link = document.getElementById(`current-file-link-${input.name}`);
+        if (!container || !link) return;
+
+        input.dataset.hasCurrent = key ? '1' : '';
+        container.classList.toggle('hidden', !key);
+        link.href = key ? `/files/${key}` : '#';
+        link.innerText = key ? key.split('/').pop() : '';
+    };
+
+    /**
+     * Atualiza a UI do formulário para o modo "Edição"
+     * @param {string} key - Chave primária como query string
+     */
+    const setEditMode = (key) => {
+        const keyValues = [...new URLSearchParams(key).values()];
+
+        formIdField.value = key;
+        form.action = `/update?${key}`;
+        formTitle.innerText = `Editando Registro #${keyValues.join(', ')}`;
+
+        // Chaves informadas pelo usuário não podem ser alteradas na edição
+        form.querySelectorAll('input[data-primary-key]').forEach(input => input.readOnly = true);
+        formSubmitBtn.innerText = 'Atualizar';
+        formCancelBtn.style.display = 'inline-block';
+    };
+
     /**
      * Reseta o formulário para o modo "Criação"
      */
@@ -283,6 +537,9 @@ document.addEventListener('DOMContentLoaded', () => {
         inputMasks.forEach(mask => mask.updateValue());
 
         formIdField.value = '';
+        if (formVersionField) formVersionField.value = '';
+        form.querySelectorAll('input[type="file"]').forEach(input => showCurrentFile(input, ''));
+        form.querySelectorAll('input[data-primary-key]').forEach(input => input.readOnly = false);
         form.action = originalFormAction;
         formTitle.innerText = originalFormTitle;
         formSubmitBtn.innerText = originalSubmitText;
@@ -307,91 +564,274 @@ document.addEventListener('DOMContentLoaded', () => {
     });
 
     // --- 5. HABILIDADES (Funções de Validação) ---
-    // (Estas são duplicatas da lógica do backend para UX)
+    // O formato de cada validação padrão (regex do email, quantidade de dígitos do CEP,
+    // telefone, CPF e CNPJ) vem do manifesto. Só o cálculo dos dígitos verificadores do CPF
+    // e do CNPJ repete o backend (validators/cpf.go e validators/cnpj.go).
 
-    const isValidEmail = (email, isRequired) => {
-        if (email === '' && !isRequired) return true;
-      
-        return /^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$/.test(email);
+    /**
+     * Confere o valor contra a validação padrão do manifesto (standard[type])
+     * @param {string} type - validation.type do campo ('' se não houver)
+     * @param {string} value - Valor preenchido
+     */
+    const matchesStandard = (type, value) => {
+        const standard = (manifest.standard || {})[type];
+        if (!standard) return true;
+        const text = standard.digits ? value.replace(/\D/g, '') : value;
+        const regex = toRegExp(standard.pattern);
+        if (regex && !regex.test(text)) return false;
+        if (!standard.checksum) return true;
+        return type === 'cpf' ? hasValidCPFDigits(text) : hasValidCNPJDigits(text);
     };
 
-    const isValidCPF = (cpf, isRequired) => {      
-        cpf = cpf.replace(/\D/g, ''); // Remove não-dígitos
+    /**
+     * Dígito verificador (módulo 11) dos dígitos de base com os pesos informados
+     * @param {string} base
+     * @param {number[]} weights
+     */
+    const checkDigit = (base, weights) => {
+        const sum = weights.reduce((total, weight, i) => total + Number(base[i]) * weight, 0);
+        const rest = sum % 11;
+        return rest < 2 ? 0 : 11 - rest;
+    };
 
-        if (cpf === '' && !isRequired) return true;
-        
-        if (cpf.length !== 11 || /^(\d)\1{10}$/.test(cpf)) return false;
+    /** @param {string} cpf - 11 dígitos */
+    const hasValidCPFDigits = (cpf) => {
+        if (/^(\d)\1{10}$/.test(cpf)) return false;
+        const d1 = checkDigit(cpf, [10, 9, 8, 7, 6, 5, 4, 3, 2]);
+        const d2 = checkDigit(cpf, [11, 10, 9, 8, 7, 6, 5, 4, 3, 2]);
+        return cpf.slice(9) === `${d1}${d2}`;
+    };
 
-        let sum = 0, rest;
+    /** @param {string} cnpj - 14 dígitos */
+    const hasValidCNPJDigits = (cnpj) => {
+        if (/^(\d)\1{13}$/.test(cnpj)) return false;
+        const d1 = checkDigit(cnpj, [5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2]);
+        const d2 = checkDigit(cnpj, [6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2]);
+        return cnpj.slice(12) === `${d1}${d2}`;
+    };
 
-        for (let i = 1; i <= 9; i++) sum += parseInt(cpf.substring(i-1, i)) * (11 - i);
-        rest = (sum * 10) % 11;
-        if ((rest === 10) || (rest === 11)) rest = 0;
-        if (rest !== parseInt(cpf.substring(9, 10))) return false;
 
-        sum = 0;
-        for (let i = 1; i <= 10; i++) sum += parseInt(cpf.substring(i-1, i)) * (12 - i);
-        rest = (sum * 10) % 11;
-        if ((rest === 10) || (rest === 11)) rest = 0;
-        if (rest !== parseInt(cpf.substring(10, 11))) return false;
+    // --- 6. EDIÇÃO NA LISTA ---
 
-        return true;
-    };
+    /**
+     * Edita uma célula da lista: o duplo clique abre o mesmo controle do formulário (máscara,
+     * opções e validação), Enter ou sair do campo salva só esse campo e Esc cancela
+     * @param {HTMLTableCellElement} cell - Célula com data-inline-field
+     */
+    const startInlineEdit = async (cell) => {
+        const row = cell.closest('tr[data-key]');
+        const name = cell.dataset.inlineField;
+        const source = document.getElementById(`field-${name}`);
+        if (cell.dataset.editing || !row || !source) return;
+        cell.dataset.editing = '1';
+
+        // Valor no formato do formulário (a célula exibe o formato da lista)
+        let value;
+        try {
+            const response = await fetch(`/get?${row.dataset.key}`);
+            if (!response.ok) throw new Error('Falha ao carregar dados');
+            const data = await response.json();
+            value = data[name] ?? '';
+        } catch (error) {
+            console.error('Falha ao buscar dados para edição:', error);
+            delete cell.dataset.editing;
+            alert('Não foi possível carregar os dados para edição.');
+            return;
+        }
 
-    const isValidCNPJ = (cnpj, isRequired) => {
-      cnpj = cnpj.replace(/[^\d]+/g, ''); // Remove caracteres não numéricos
-      
-      if (cnpj === '' && !isRequired) return true;
-      
-      if (cnpj.length !== 14) return false; // Verifica se o CNPJ tem 14 dígitos
-  
-      // Validação de CNPJ com números repetidos (ex: 11111111111111)
-      if (/^(.)\1{13}$/.test(cnpj)) return false;
-  
-      // Validação do primeiro dígito verificador
-      let soma = 0;
-      let peso = [6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2];
-      for (let i = 0; i < 12; i++) {
-          soma += parseInt(cnpj[i]) * peso[i + 1];
-      }
-      let digito1 = 11 - (soma % 11);
-      digito1 = digito1 === 10 || digito1 === 11 ? 0 : digito1;
-  
-      // Validação do segundo dígito verificador
-      soma = 0;
-      peso = [5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2];
-      for (let i = 0; i < 13; i++) {
-          soma += parseInt(cnpj[i]) * peso[i];
-      }
-      let digito2 = 11 - (soma % 11);
-      digito2 = digito2 === 10 || digito2 === 11 ? 0 : digito2;
-  
-      // Verifica se os dígitos calculados são iguais aos do CNPJ
-      return cnpj[12] == digito1 && cnpj[13] == digito2;
-    };
-    
-    // NOVO: Validação de Telefone
-    const isValidTelefone = (telefone, isRequired) => {        
-        telefone = telefone.replace(/\D/g, ''); // Remove não-dígitos
-        
-        if (telefone === '' && !isRequired) return true;
-        
-        // Aceita 10 (DDD + 8 dígitos) ou 11 (DDD + 9 + 8 dígitos)
-        return telefone.length >= 10 && telefone.length <= 11;
-    };
-    
-    // NOVO: Validação de CEP
-    const isValidCEP = (cep, isRequired) => {
-        cep = cep.replace(/\D/g, ''); // Remove não-dígitos  
+        const original = cell.innerHTML;
+        const input = source.cloneNode(true);
+        input.removeAttribute('id');
+        input.classList.remove('border-red-500', 'ring-1', 'ring-red-500');
+        if (input.type === 'checkbox') {
+            input.checked = value === '1';
+        } else {
+            input.value = value;
+        }
+        const errorText = document.createElement('p');
+        errorText.className = 'text-red-600 text-xs mt-1 hidden';
+        cell.replaceChildren(input, errorText);
+        const mask = input.dataset.mask ? IMask(input, maskOptionsFor(input.dataset.mask)) : null;
+        input.focus();
+
+        const currentValue = () => input.type === 'checkbox' ? (input.checked ? '1' : '0') : input.value;
+        const initialValue = currentValue();
+        let lastFailed = null;
+        let saving = false;
+
+        const close = () => {
+            mask?.destroy();
+            delete cell.dataset.editing;
+        };
+        const showInlineError = (message) => {
+            lastFailed = currentValue();
+            errorText.innerText = message;
+            errorText.classList.remove('hidden');
+            input.classList.add('border-red-500', 'ring-1', 'ring-red-500');
+        };
+        const cancel = () => {
+            close();
+            cell.innerHTML = original;
+        };
+
+        const save = async () => {
+            const newValue = currentValue();
+            if (saving || !cell.dataset.editing || newValue === lastFailed) return;
+            if (newValue === initialValue) {
+                cancel();
+                return;
+            }
+            const message = fieldError(input);
+            if (message) {
+                showInlineError(message);
+                return;
+            }
+
+            const body = { [name]: newValue };
+            if (formVersionField && row.dataset.version) {
+                body[formVersionField.name] = row.dataset.version;
+            }
+
+            saving = true;
+            try {
+                const response = await fetch(`/api/record?${row.dataset.key}`, {
+                    method: 'PATCH',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify(body),
+                });
+                const result = await response.json();
+                if (!response.ok) {
+                    showInlineError(result.errors?.[name] || result.error || 'Não foi possível salvar.');
+                    return;
+                }
+                if (formVersionField) {
+                    row.dataset.version = result.data[formVersionField.name] ?? '';
+                }
+                close();
+                cell.textContent = result.display[name] ?? '';
+            } catch (error) {
+                console.error('Falha ao salvar o campo:', error);
+                showInlineError('Não foi possível salvar.');
+            } finally {
+                saving = false;
+            }
+        };
 
-        if (cep === '' && !isRequired) return true;
+        input.addEventListener('keydown', (e) => {
+            if (e.key === 'Escape') {
+                cancel();
+            } else if (e.key === 'Enter' && input.tagName !== 'TEXTAREA') {
+                e.preventDefault();
+                save();
+            }
+        });
+        input.addEventListener('input', () => {
+            errorText.classList.add('hidden');
+            input.classList.remove('border-red-500', 'ring-1', 'ring-red-500');
+            lastFailed = null;
+        });
+        if (input.tagName === 'SELECT' || input.type === 'checkbox') {
+            input.addEventListener('change', save);
+        }
+        input.addEventListener('blur', save);
+    };
 
-        // O CEP sempre deve ter 8 dígitos
-        return cep.length === 8;
+    /**
+     * Liga o duplo clique às células editáveis da lista
+     */
+    const initInlineEdit = () => {
+        document.querySelectorAll('[data-inline-field]').forEach(cell => {
+            cell.addEventListener('dblclick', () => startInlineEdit(cell));
+        });
     };
-    
 
     // --- INICIA A MÁGICA ---
     initMasks();
     initValidation();
+    initInlineEdit();
+
+    // Se o servidor devolveu o formulário de edição (erro ou conflito), mantém o modo "Edição"
+    if (formIdField.value) {
+        setEditMode(formIdField.value);
+    }
+});
+
+// --- Ações em massa nos registros selecionados da lista ---
+document.addEventListener('DOMContentLoaded', () => {
+    const bulkForm = document.querySelector('[data-bulk-form]');
+    if (!bulkForm) return;
+
+    const selectAll = document.querySelector('[data-bulk-all]');
+    const checkboxes = Array.from(document.querySelectorAll('[data-bulk-select]'));
+    const action = bulkForm.querySelector('[data-bulk-action]');
+    const count = bulkForm.querySelector('[data-bulk-count]');
+    const submit = bulkForm.querySelector('[data-bulk-submit]');
+    const fieldSelect = bulkForm.querySelector('[data-bulk-field]');
+    const valueInput = bulkForm.querySelector('[data-bulk-value]');
+
+    const selected = () => checkboxes.filter(cb => cb.checked).length;
+
+    // Atualiza o contador, o botão e o estado do "selecionar todos"
+    const updateSelection = () => {
+        const total = selected();
+        count.textContent = total ? `${total} selecionado(s):` : 'Ações nos selecionados:';
+        submit.disabled = total === 0;
+        submit.classList.toggle('opacity-50', total === 0);
+        if (selectAll) {
+            selectAll.checked = total > 0 && total === checkboxes.length;
+            selectAll.indeterminate = total > 0 && total < checkboxes.length;
+        }
+    };
+
+    // Exibe só as opções da ação escolhida (formato na exportação, campo e valor na edição)
+    const updateAction = () => {
+        bulkForm.querySelectorAll('[data-bulk-option]').forEach(el => {
+            el.hidden = el.dataset.bulkOption !== action.value;
+        });
+    };
+
+    // Ajusta o input do novo valor ao controle do campo escolhido
+    const updateValueInput = () => {
+        if (!fieldSelect || !valueInput) return;
+        const option = fieldSelect.selectedOptions[0];
+        let widget = option ? option.dataset.widget : 'text';
+        valueInput.placeholder = 'Novo valor (vazio limpa o campo)';
+        if (widget === 'checkbox') {
+            widget = 'text';
+            valueInput.placeholder = 'sim ou não';
+        } else if (!['number', 'date', 'datetime-local', 'time', 'email'].includes(widget)) {
+            widget = 'text';
+        }
+        valueInput.type = widget;
+        if (option && option.dataset.step) {
+            valueInput.step = option.dataset.step;
+        } else {
+            valueInput.removeAttribute('step');
+        }
+    };
+
+    if (selectAll) {
+        selectAll.addEventListener('change', () => {
+            checkboxes.forEach(cb => { cb.checked = selectAll.checked; });
+            updateSelection();
+        });
+    }
+    checkboxes.forEach(cb => cb.addEventListener('change', updateSelection));
+    action.addEventListener('change', updateAction);
+    if (fieldSelect) fieldSelect.addEventListener('change', updateValueInput);
+
+    bulkForm.addEventListener('submit', (e) => {
+        const total = selected();
+        if (total === 0) {
+            e.preventDefault();
+            return;
+        }
+        if (action.value === 'delete' && !confirm(`Excluir ${total} registro(s)? Esta ação não pode ser desfeita.`)) {
+            e.preventDefault();
+        }
+    });
+
+    updateSelection();
+    updateAction();
+    updateValueInput();
 });
@@ -0,0 +1,132 @@
+/**
+ * rules.js
+ * Linguagem das regras entre campos ("rules" do schema), a mesma de models/rules.go:
+ * campos, 'textos', números, true/false/null, == != < <= > >=, && || !, parênteses,
+ * filled() e empty(). Os dois lados são conferidos pelos mesmos casos em
+ * models/testdata/rule_cases.json.
+ */
+(function (global) {
+
+    // Vazio, false e '' são falsos
+    const truthy = (v) => v !== null && v !== undefined && v !== false && v !== '';
+
+    // Números em texto (ex.: decimal) são comparados como números
+    const ruleNumber = (v) => {
+        if (typeof v === 'number') return v;
+        if (typeof v === 'string' && /^[+-]?\d+(\.\d+)?$/.test(v)) return Number(v);
+        return null;
+    };
+
+    /**
+     * Compara dois valores: como números se os dois forem números, senão como texto.
+     * Comparação de ordem com um lado vazio não se aplica (é verdadeira).
+     */
+    const compare = (op, a, b) => {
+        if (a === undefined) a = null;
+        if (b === undefined) b = null;
+        if (op === '==' || op === '!=') {
+            let equal;
+            if (a === null || b === null) equal = a === b;
+            else if (ruleNumber(a) !== null && ruleNumber(b) !== null) equal = ruleNumber(a) === ruleNumber(b);
+            else equal = String(a) === String(b);
+            return op === '==' ? equal : !equal;
+        }
+        if (a === null || b === null) return true;
+        let cmp;
+        if (ruleNumber(a) !== null && ruleNumber(b) !== null) cmp = ruleNumber(a) - ruleNumber(b);
+        else cmp = String(a) < String(b) ? -1 : (String(a) > String(b) ? 1 : 0);
+        return { '<': cmp < 0, '<=': cmp <= 0, '>': cmp > 0, '>=': cmp >= 0 }[op];
+    };
+
+    /**
+     * Interpreta uma expressão de regra
+     * @param {string} src
+     * @returns {{eval: function(function(string): *): boolean, idents: string[]}}
+     */
+    const parse = (src) => {
+        const tokens = src.match(/'[^']*'|"[^"]*"|\d[\d.]*|[A-Za-z_]\w*|==|!=|<=|>=|&&|\|\||[<>!()]|\S/g) || [];
+        const idents = [];
+        let pos = 0;
+        const peek = () => tokens[pos];
+        const accept = (op) => (tokens[pos] === op ? (pos++, true) : false);
+        const fail = (what) => { throw new Error(`Regra inválida: ${what} em "${src}"`); };
+
+        const parseOr = () => {
+            let left = parseAnd();
+            while (accept('||')) {
+                const l = left, r = parseAnd();
+                left = (get) => truthy(l(get)) || truthy(r(get));
+            }
+            return left;
+        };
+        const parseAnd = () => {
+            let left = parseNot();
+            while (accept('&&')) {
+                const l = left, r = parseNot();
+                left = (get) => truthy(l(get)) && truthy(r(get));
+            }
+            return left;
+        };
+        const parseNot = () => {
+            if (accept('!')) {
+                const operand = parseNot();
+                return (get) => !truthy(operand(get));
+            }
+            return parseComparison();
+        };
+        const parseComparison = () => {
+            const left = parseTerm();
+            const op = ['==', '!=', '<=', '>=', '<', '>'].find(candidate => accept(candidate));
+            if (!op) return left;
+            const right = parseTerm();
+            return (get) => compare(op, left(get), right(get));
+        };
+        const parseTerm = () => {
+            const tok = tokens[pos++];
+            if (tok === undefined) fail('expressão incompleta');
+            if (tok[0] === "'" || tok[0] === '"') {
+                if (tok.length < 2 || tok[tok.length - 1] !== tok[0]) fail('texto sem aspas de fechamento');
+                const text = tok.slice(1, -1);
+                return () => text;
+            }
+            if (/^\d/.test(tok)) {
+                if (!/^\d+(\.\d*)?$/.test(tok)) fail(`número inválido "${tok}"`);
+                const n = Number(tok);
+                return () => n;
+            }
+            if (tok === 'true' || tok === 'false') { const b = tok === 'true'; return () => b; }
+            if (tok === 'null') return () => null;
+            if (tok === '(') {
+                const inner = parseOr();
+                if (!accept(')')) fail('falta ")"');
+                return inner;
+            }
+            if (/^[A-Za-z_]/.test(tok)) {
+                if (accept('(')) {
+                    if (tok !== 'filled' && tok !== 'empty') fail(`função desconhecida "${tok}"`);
+                    const arg = parseOr();
+                    if (!accept(')')) fail('falta ")"');
+                    return (get) => {
+                        const value = arg(get);
+                        const filled = value !== null && value !== undefined && value !== '';
+                        return tok === 'empty' ? !filled : filled;
+                    };
+                }
+                if (!idents.includes(tok)) idents.push(tok);
+                return (get) => get(tok);
+            }
+            fail(`"${tok}" inesperado`);
+        };
+
+        const root = parseOr();
+        if (peek() !== undefined) fail(`"${peek()}" inesperado`);
+        return { eval: (get) => truthy(root(get)), idents };
+    };
+
+    const CrudRules = { parse, truthy };
+    if (typeof module !== 'undefined' && module.exports) {
+        module.exports = CrudRules; // Testes (node)
+    } else {
+        global.CrudRules = CrudRules;
+    }
+})(this);
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ErrNotFound indica que o arquivo não existe no backend
+var ErrNotFound = errors.New("arquivo não encontrado")
+
+// ErrInvalidKey indica uma chave com caracteres não permitidos (ex.: "..")
+var ErrInvalidKey = errors.New("chave de arquivo inválida")
+
+// Chaves são caminhos relativos gerados pela aplicação (ex.: "clientes/foto/01HV...X.jpg")
+var keyRegex = regexp.MustCompile(`^[A-Za-z0-9_\-]+(/[A-Za-z0-9_\-]+)*(\.[A-Za-z0-9]+)?$`)
+
+// Storage é o backend onde os arquivos enviados pelos formulários são guardados.
+// A interface segue o modelo de object storage (chave → conteúdo), então um bucket
+// S3 (ou compatível: MinIO, R2, ...) e um diretório local são intercambiáveis.
+type Storage interface {
+	// Save grava o conteúdo sob a chave, sobrescrevendo se já existir
+	Save(key string, r io.Reader, contentType string) error
+	// Open abre o conteúdo da chave para leitura; retorna ErrNotFound se não existir
+	Open(key string) (io.ReadCloser, error)
+	// Delete remove a chave; remover uma chave inexistente não é erro
+	Delete(key string) error
+}
+
+// ValidKey verifica se a chave é um caminho relativo seguro
+func ValidKey(key string) bool {
+	return keyRegex.MatchString(key) && !strings.Contains(key, "..")
+}
+
+// ContentTypeByKey deduz o Content-Type pela extensão da chave
+func ContentTypeByKey(key string) string {
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
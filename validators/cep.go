@@ -9,5 +9,5 @@ func IsValidCEP(cep string, required bool) bool {
 		return true
 	}
 	
-	return cepRegex.MatchString(cep)
+	return cepRegex.MatchString(justDigits(cep))
 }
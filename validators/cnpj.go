@@ -1,5 +1,9 @@
 package validators
 
+import "regexp"
+
+var cnpjRegex = regexp.MustCompile(`^\d{14}$`)
+
 // IsValidCNPJ valida um CNPJ
 func IsValidCNPJ(cnpj string, required bool) bool {
 	if (!required) {
@@ -7,20 +11,24 @@ func IsValidCNPJ(cnpj string, required bool) bool {
 	}
 	
 	cnpj = justDigits(cnpj)
-	if len(cnpj) != 14 {
+	if !cnpjRegex.MatchString(cnpj) {
 		return false
 	}
 	if allSameDigits(cnpj) {
 		return false
 	}
 
+	return cnpj[12:] == CNPJCheckDigits(cnpj[:12])
+}
+
+// CNPJCheckDigits calcula os dois dígitos verificadores a partir dos 12 primeiros dígitos do CNPJ
+func CNPJCheckDigits(base string) string {
 	weights1 := []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
 	weights2 := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
 
-	d1 := calculateCNPJDigit(cnpj[:12], weights1)
-	d2 := calculateCNPJDigit(cnpj[:13], weights2)
-
-	return cnpj[12] == d1 && cnpj[13] == d2
+	d1 := calculateCNPJDigit(base, weights1)
+	d2 := calculateCNPJDigit(base+string(rune(d1)), weights2)
+	return string([]byte{d1, d2})
 }
 
 func calculateCNPJDigit(doc string, weights []int) uint8 {
@@ -1,5 +1,9 @@
 package validators
 
+import "regexp"
+
+var cpfRegex = regexp.MustCompile(`^\d{11}$`)
+
 // IsValidCPF valida um CPF
 func IsValidCPF(cpf string, required bool) bool {
 	if (!required) {
@@ -7,17 +11,21 @@ func IsValidCPF(cpf string, required bool) bool {
 	}
 	
 	cpf = justDigits(cpf)
-	if len(cpf) != 11 {
+	if !cpfRegex.MatchString(cpf) {
 		return false
 	}
 	if allSameDigits(cpf) {
 		return false
 	}
 
-	d1 := calculateDigit(cpf[:9], 10)
-	d2 := calculateDigit(cpf[:10], 11)
+	return cpf[9:] == CPFCheckDigits(cpf[:9])
+}
 
-	return cpf[9] == d1 && cpf[10] == d2
+// CPFCheckDigits calcula os dois dígitos verificadores a partir dos 9 primeiros dígitos do CPF
+func CPFCheckDigits(base string) string {
+	d1 := calculateDigit(base, 10)
+	d2 := calculateDigit(base+string(rune(d1)), 11)
+	return string([]byte{d1, d2})
 }
 
 func allSameDigits(s string) bool {
@@ -0,0 +1,115 @@
+package validators
+
+import (
+	"go-crud-generator/models"
+)
+
+// Messages são as mensagens de erro da validação, as mesmas no servidor (ValidateField) e
+// no navegador, que as recebe pelo manifesto. %d é o limite da regra (caracteres, casas
+// decimais ou dígitos).
+var Messages = map[string]string{
+	"required":     "Campo obrigatório",
+	"cpf":          "CPF inválido",
+	"cnpj":         "CNPJ inválido",
+	"email":        "Email inválido",
+	"cep":          "CEP inválido",
+	"telefone":     "Telefone inválido",
+	"enum":         "Selecione uma das opções válidas",
+	"integer":      "Valor deve ser um número inteiro",
+	"int_range":    "Valor deve estar entre -2147483648 e 2147483647",
+	"bigint_range": "Valor deve estar entre -9223372036854775808 e 9223372036854775807",
+	"number":       "Valor deve ser numérico",
+	"date":         "Data inválida. Use AAAA-MM-DD",
+	"datetime":     "Data e hora inválidas. Use AAAA-MM-DD HH:MM",
+	"time":         "Hora inválida. Use HH:MM",
+	"uuid":         "UUID inválido",
+	"json":         "JSON inválido",
+	"max_length":   "Máximo de %d caracteres",
+	"scale":        "Use no máximo %d casas decimais",
+	"precision":    "Use no máximo %d dígitos antes da vírgula",
+}
+
+// StandardValidation é o formato de uma validação padrão (validation.type), o mesmo
+// usado por IsValidEmail, IsValidCEP...
+type StandardValidation struct {
+	Pattern  string `json:"pattern"`            // Regex (sintaxe do Go) que o valor precisa atender
+	Digits   bool   `json:"digits,omitempty"`   // A regex vale para os dígitos do valor (com ou sem máscara)
+	Checksum bool   `json:"checksum,omitempty"` // Confere também os dígitos verificadores (CPF e CNPJ)
+}
+
+// StandardValidations são os formatos das validações padrão, por validation.type
+var StandardValidations = map[string]StandardValidation{
+	"email":    {Pattern: emailRegex.String()},
+	"cep":      {Pattern: cepRegex.String(), Digits: true},
+	"telefone": {Pattern: phoneRegex.String(), Digits: true},
+	"cpf":      {Pattern: cpfRegex.String(), Digits: true, Checksum: true},
+	"cnpj":     {Pattern: cnpjRegex.String(), Digits: true, Checksum: true},
+}
+
+// Manifest descreve as validações do schema para o navegador (servido em /api/validation):
+// o main.js valida o formulário com estas regras em vez de repeti-las, então as duas pontas
+// sempre concordam. Só o cálculo dos dígitos verificadores do CPF e do CNPJ é
+// reimplementado em JavaScript.
+type Manifest struct {
+	Table    string                        `json:"table"`
+	Fields   []FieldManifest               `json:"fields"`
+	Rules    []models.Rule                 `json:"rules"`
+	Messages map[string]string             `json:"messages"`
+	Standard map[string]StandardValidation `json:"standard"`
+}
+
+// FieldManifest são as validações de um campo, na ordem em que ValidateField as aplica:
+// obrigatório, validação padrão, enum, regex_rules e por fim o tipo
+type FieldManifest struct {
+	Name       string             `json:"name"`
+	Type       string             `json:"type"` // Tipo base (ex.: "decimal", sem precisão)
+	Required   bool               `json:"required"`
+	Validation string             `json:"validation,omitempty"` // validation.type (cpf, cnpj, email, cep, telefone)
+	Mask       string             `json:"mask,omitempty"`       // Os separadores são removidos antes do limite de tamanho
+	Enum       []string           `json:"enum,omitempty"`
+	RegexRules []models.RegexRule `json:"regex_rules,omitempty"`
+	MaxLength  int                `json:"max_length,omitempty"` // string
+	Precision  int                `json:"precision,omitempty"`  // decimal
+	Scale      int                `json:"scale,omitempty"`      // decimal
+	Remote     bool               `json:"remote,omitempty"`     // Conferido também no banco (/api/validate): unique, chave ou chave estrangeira
+}
+
+// BuildManifest gera o manifesto de validação do schema. Chaves geradas e uploads ficam
+// de fora, como em ValidateData.
+func BuildManifest(schema *models.Schema) Manifest {
+	manifest := Manifest{
+		Table:    schema.TableName,
+		Fields:   []FieldManifest{},
+		Rules:    schema.Rules,
+		Messages: Messages,
+		Standard: StandardValidations,
+	}
+	if manifest.Rules == nil {
+		manifest.Rules = []models.Rule{}
+	}
+
+	singleKey := len(schema.PrimaryKeyFields()) == 1
+	for _, field := range schema.Fields {
+		if schema.IsGeneratedKey(field) || field.IsUpload() {
+			continue
+		}
+		entry := FieldManifest{
+			Name:       field.Name,
+			Type:       field.BaseType(),
+			Required:   field.Required || field.PrimaryKey, // Chaves informadas são sempre obrigatórias
+			Validation: field.Validation.Type,
+			Mask:       field.Mask,
+			Enum:       field.Enum,
+			RegexRules: field.Validation.RegexRules,
+			Remote:     field.Unique || field.PrimaryKey && singleKey,
+		}
+		switch entry.Type {
+		case "string":
+			entry.MaxLength = field.MaxLength()
+		case "decimal":
+			entry.Precision, entry.Scale, _ = field.DecimalSpec()
+		}
+		manifest.Fields = append(manifest.Fields, entry)
+	}
+	return manifest
+}
@@ -9,5 +9,5 @@ func IsValidPhone(phone string, required bool) bool {
 		return true
 	}
 
-	return phoneRegex.MatchString(phone)
+	return phoneRegex.MatchString(justDigits(phone))
 }
@@ -1,15 +1,24 @@
 package validators
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/url"
 	"regexp"
-	"go-crud-generator/models"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
+
+	"go-crud-generator/models"
 )
 
+var decimalRegex = regexp.MustCompile(`^[+-]?\d*\.?\d+$|^[+-]?\d+\.$`)
+
 const (
 	ANY_MASK       = '*'
 	CHARACTER_MASK = '#'
@@ -18,16 +27,14 @@ const (
 
 func CleanValueByMask(field models.Field, value string) string {
 
-    if field.Mask == "" {
+	if field.Mask == "" {
 		return value
 	}
 
-	charsToRemove := []string{".", "-", "(", ")", "/", " ", "_"}
-
 	cleanedValue := value
 
-	for _, char := range charsToRemove {
-		cleanedValue = strings.ReplaceAll(cleanedValue, char, "")
+	for _, char := range models.MaskSeparators {
+		cleanedValue = strings.ReplaceAll(cleanedValue, string(char), "")
 	}
 
 	return cleanedValue
@@ -109,32 +116,75 @@ func FormatValueByMask(mask string, value string) string {
 	return string(result)
 }
 
+// FormatDataBySchema formata os registros para exibição na lista (ver FormatDisplayValue)
 func FormatDataBySchema(schema *models.Schema, data []map[string]interface{}) {
 	for _, record := range data {
 		for _, field := range schema.Fields {
-
-			if field.Mask != "" {
-
-				if rawValue, ok := record[field.Name].(string); ok {
-
-					formattedValue := FormatValueByMask(field.Mask, rawValue)
-
-					record[field.Name] = formattedValue
-				}
+			if value, ok := record[field.Name]; ok && value != nil {
+				record[field.Name] = FormatDisplayValue(field, value)
 			}
 		}
 	}
 }
 
+// FormatSingleDataBySchema formata um registro para preencher o formulário de edição (ver FormatFormValue)
 func FormatSingleDataBySchema(schema *models.Schema, data map[string]interface{}) {
 	for _, field := range schema.Fields {
-		if field.Mask != "" {
-			if rawValue, ok := data[field.Name].(string); ok {
-				formattedValue := FormatValueByMask(field.Mask, rawValue)
-				data[field.Name] = formattedValue
-			}
+		if value, ok := data[field.Name]; ok && value != nil {
+			data[field.Name] = FormatFormValue(field, value)
+		}
+	}
+}
+
+// FormatFormValue converte um valor vindo do banco para o texto que o input do
+// formulário espera (ex.: date "2006-01-02", datetime-local "2006-01-02T15:04", bool "1"/"0")
+func FormatFormValue(field models.Field, value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		if field.BaseType() == "datetime" {
+			return v.Format("2006-01-02T15:04")
 		}
+		return v.Format("2006-01-02")
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case json.RawMessage:
+		return string(v)
+	case string:
+		return FormatValueByMask(field.Mask, v)
+	}
+	return fmt.Sprint(value)
+}
+
+// FormatDisplayValue converte um valor vindo do banco para exibição
+// (ex.: datas DD/MM/AAAA, bool Sim/Não, JSON compacto, máscara aplicada)
+func FormatDisplayValue(field models.Field, value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		if field.BaseType() == "datetime" {
+			return v.Format("02/01/2006 15:04")
+		}
+		return v.Format("02/01/2006")
+	case bool:
+		if v {
+			return "Sim"
+		}
+		return "Não"
+	case json.RawMessage:
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, v); err != nil {
+			return string(v)
+		}
+		return buf.String()
+	case string:
+		if field.BaseType() == "time" && len(v) >= 5 {
+			return v[:5] // HH:MM
+		}
+		return FormatValueByMask(field.Mask, v)
 	}
+	return fmt.Sprint(value)
 }
 
 // ValidateData valida os dados de um formulário contra o schema e converte tipos
@@ -144,106 +194,290 @@ func ValidateData(form url.Values, schema *models.Schema) (map[string]interface{
 	errors := make(map[string]string)
 
 	for _, field := range schema.Fields {
-		value := form.Get(field.Name)
+		// Chaves geradas (auto-increment, uuid, ulid) não vêm do formulário
+		if schema.IsGeneratedKey(field) {
+			continue
+		}
 
-		// 1. Verificar campos obrigatórios
-		if field.Required && value == "" {
-			errors[field.Name] = "Campo obrigatório"
+		// Arquivos chegam como multipart e são tratados pelo controller
+		if field.IsUpload() {
 			continue
 		}
 
-		// Se não for obrigatório e estiver vazio, pulamos o resto
-		if !field.Required && value == "" {
-			cleanData[field.Name] = nil // Insere NULL no DB
+		value, msg := ValidateField(field, form[field.Name])
+		if msg != "" {
+			errors[field.Name] = msg
 			continue
 		}
+		cleanData[field.Name] = value
+	}
 
-		// 2. Validações Padrão (CPF, CNPJ, etc.)
-		switch field.Validation.Type {
-		case "cpf":
-			if !IsValidCPF(value, field.Required) {
-				errors[field.Name] = "CPF inválido"
-				continue
-			}
-			// Limpa o CPF para salvar no DB (opcional)
-			// value = "somente numeros"
-		case "cnpj":
-			if !IsValidCNPJ(value, field.Required) {
-				errors[field.Name] = "CNPJ inválido"
-				continue
-			}
-		case "email":
-			if !IsValidEmail(value, field.Required) {
-				errors[field.Name] = "Email inválido"
-				continue
-			}
-		case "cep":
-			if !IsValidCEP(value, field.Required) {
-				errors[field.Name] = "CEP inválido"
-				continue
+	CheckRules(schema, cleanData, nil, errors)
+	return cleanData, errors
+}
+
+// CheckRules avalia as regras entre campos do schema (ver models.Rule) sobre os valores
+// limpos do registro e acrescenta os erros em errors, na chave de cada campo da regra
+// ("_form" se ela não nomear campos). Regras que citam um campo já com erro ficam de fora;
+// com changed (atualização parcial), também as que não citam nenhum campo alterado.
+func CheckRules(schema *models.Schema, values map[string]interface{}, changed map[string]interface{}, errors map[string]string) {
+	if len(schema.Rules) == 0 {
+		return
+	}
+
+	normalized := make(map[string]interface{}, len(schema.Fields))
+	for _, field := range schema.Fields {
+		normalized[field.Name] = models.RuleValue(field, values[field.Name])
+	}
+
+	for _, rule := range schema.Rules {
+		refs, err := rule.References()
+		if err != nil {
+			errors["_form"] = fmt.Sprintf("Regra inválida no schema (%s): %v", rule.Check, err)
+			continue
+		}
+		relevant := changed == nil
+		skip := false
+		for _, name := range append(refs, rule.Fields...) {
+			if _, failed := errors[name]; failed {
+				skip = true
 			}
-		case "telefone":
-			if !IsValidPhone(value, field.Required) {
-				errors[field.Name] = "Telefone inválido"
-				continue
+			if _, ok := changed[name]; ok {
+				relevant = true
 			}
 		}
+		if skip || !relevant {
+			continue
+		}
 
-		// 3. Validações de Regex Customizadas
-		for _, rule := range field.Validation.RegexRules {
-			matched, _ := regexp.MatchString(rule.Pattern, value)
-			if !matched {
-				errors[field.Name] = rule.Message
-				continue // Para no primeiro erro de regex
+		if ok, _ := rule.Holds(normalized); ok {
+			continue
+		}
+		if len(rule.Fields) == 0 {
+			if _, exists := errors["_form"]; !exists {
+				errors["_form"] = rule.Message
 			}
 		}
+		for _, name := range rule.Fields {
+			errors[name] = rule.Message
+		}
+	}
+}
 
-		// 4. Conversão de Tipo
-		// Se passou nas validações, converte para o tipo correto
-		if _, hasError := errors[field.Name]; !hasError {
-			switch field.Type {
-			case "int":
-				if field.PrimaryKey && value == "" {
-					// É um create, o ID não é enviado
-					continue
-				}
-				intVal, err := strconv.Atoi(value)
-				if err != nil {
-					errors[field.Name] = "Valor deve ser um número inteiro"
-				} else {
-					cleanData[field.Name] = intVal
-				}
-			case "date":
-				// Tenta parsear formatos comuns (YYYY-MM-DD do HTML5 ou DD/MM/YYYY)
-				dateVal, err := time.Parse("2006-01-02", value)
-				if err != nil {
-					dateVal, err = time.Parse("02/01/2006", value)
-					if err != nil {
-						errors[field.Name] = "Data inválida. Use AAAA-MM-DD"
-					} else {
-						cleanData[field.Name] = dateVal
-					}
-				} else {
-					cleanData[field.Name] = dateVal
-				}
-			case "float":
-				floatVal, err := strconv.ParseFloat(value, 64)
-				if err != nil {
-					errors[field.Name] = "Valor deve ser numérico"
-				} else {
-					cleanData[field.Name] = floatVal
-				}
-			case "string", "text":
-				cleanData[field.Name] = CleanValueByMask(field, value)
-			default:
-				cleanData[field.Name] = value
-			}
+// ValidatePartial valida só os campos presentes no formulário, para uma atualização parcial
+// (PATCH): campos ausentes ficam fora do resultado e não são alterados, então o obrigatório
+// só vale para os campos enviados. Um campo enviado vazio ("campo=") grava NULL. As regras
+// entre campos dependem do registro gravado e ficam com o controller (ver CheckRules).
+func ValidatePartial(form url.Values, schema *models.Schema) (map[string]interface{}, map[string]string) {
+	cleanData := make(map[string]interface{})
+	errors := make(map[string]string)
+
+	for _, field := range schema.Fields {
+		if _, sent := form[field.Name]; !sent || schema.IsGeneratedKey(field) || field.IsUpload() {
+			continue
+		}
+
+		value, msg := ValidateField(field, form[field.Name])
+		if msg != "" {
+			errors[field.Name] = msg
+			continue
 		}
+		cleanData[field.Name] = value
 	}
 
 	return cleanData, errors
 }
 
+// ValidateField valida e converte o valor de um campo, enviado como no formulário
+// (values são os valores do campo no url.Values). Retorna o valor limpo (nil para um campo
+// opcional vazio) ou a mensagem de erro.
+func ValidateField(field models.Field, values []string) (interface{}, string) {
+	// Checkbox: o formulário envia um "0" oculto e, se marcado, o "1" do checkbox
+	if field.Type == "bool" {
+		return parseBool(values), ""
+	}
+
+	value := ""
+	if len(values) > 0 {
+		value = values[0]
+	}
+
+	// Chaves informadas pelo usuário são sempre obrigatórias
+	if field.PrimaryKey {
+		field.Required = true
+	}
+
+	// 1. Verificar campos obrigatórios
+	if field.Required && value == "" {
+		return nil, Messages["required"]
+	}
+
+	// Se não for obrigatório e estiver vazio, pulamos o resto
+	if !field.Required && value == "" {
+		return nil, "" // Insere NULL no DB
+	}
+
+	// 2. Validações Padrão (CPF, CNPJ, etc.): o valor não está vazio, então o formato
+	// vale também para campos opcionais (como no navegador)
+	switch field.Validation.Type {
+	case "cpf":
+		if !IsValidCPF(value, true) {
+			return nil, Messages["cpf"]
+		}
+	case "cnpj":
+		if !IsValidCNPJ(value, true) {
+			return nil, Messages["cnpj"]
+		}
+	case "email":
+		if !IsValidEmail(value, true) {
+			return nil, Messages["email"]
+		}
+	case "cep":
+		if !IsValidCEP(value, true) {
+			return nil, Messages["cep"]
+		}
+	case "telefone":
+		if !IsValidPhone(value, true) {
+			return nil, Messages["telefone"]
+		}
+	}
+
+	// Valores fora da lista do enum
+	if len(field.Enum) > 0 && !slices.Contains(field.Enum, value) {
+		return nil, Messages["enum"]
+	}
+
+	// 3. Validações de Regex Customizadas (para no primeiro erro)
+	for _, rule := range field.Validation.RegexRules {
+		if !rule.Match(value) {
+			return nil, rule.Message
+		}
+	}
+
+	// 4. Conversão de Tipo
+	// Se passou nas validações, converte para o tipo correto
+	switch field.BaseType() {
+	case "int":
+		// INT do MySQL: 32 bits, qualquer que seja o tamanho do int do Go
+		intVal, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, integerError(err, "int_range")
+		}
+		return int(intVal), ""
+	case "date":
+		// Tenta parsear formatos comuns (YYYY-MM-DD do HTML5 ou DD/MM/YYYY)
+		dateVal, err := parseTimeLayouts(value, "2006-01-02", "02/01/2006")
+		if err != nil {
+			return nil, Messages["date"]
+		}
+		return dateVal, ""
+	case "float":
+		floatVal, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, Messages["number"]
+		}
+		return floatVal, ""
+	case "bigint":
+		bigVal, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, integerError(err, "bigint_range")
+		}
+		return bigVal, ""
+	case "datetime":
+		// datetime-local do HTML5 (com ou sem segundos) ou DD/MM/YYYY HH:MM
+		dateTimeVal, err := parseTimeLayouts(value, "2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02 15:04:05", "02/01/2006 15:04")
+		if err != nil {
+			return nil, Messages["datetime"]
+		}
+		return dateTimeVal, ""
+	case "time":
+		timeVal, err := parseTimeLayouts(value, "15:04", "15:04:05")
+		if err != nil {
+			return nil, Messages["time"]
+		}
+		return timeVal.Format("15:04:05"), ""
+	case "uuid":
+		if !models.IsUUID(value) {
+			return nil, Messages["uuid"]
+		}
+		return strings.ToLower(value), ""
+	case "json":
+		if !json.Valid([]byte(value)) {
+			return nil, Messages["json"]
+		}
+		return value, ""
+	case "decimal":
+		return parseDecimal(field, value)
+	case "string", "text":
+		cleaned := CleanValueByMask(field, value)
+		if field.BaseType() == "string" && utf8.RuneCountInString(cleaned) > field.MaxLength() {
+			return nil, fmt.Sprintf(Messages["max_length"], field.MaxLength())
+		}
+		return cleaned, ""
+	}
+	return value, ""
+}
+
+// integerError é a mensagem de um inteiro rejeitado por strconv.ParseInt: fora do
+// intervalo da coluna (rangeMessage) ou não numérico
+func integerError(err error, rangeMessage string) string {
+	if errors.Is(err, strconv.ErrRange) {
+		return Messages[rangeMessage]
+	}
+	return Messages["integer"]
+}
+
+// parseTimeLayouts tenta interpretar o valor com cada layout, na ordem
+func parseTimeLayouts(value string, layouts ...string) (time.Time, error) {
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// parseDecimal valida um valor decimal contra a precisão e a escala do campo e o
+// normaliza como string (ex.: "1234,5" → "1234.5") para não perder precisão.
+// Retorna a mensagem de erro quando inválido.
+func parseDecimal(field models.Field, value string) (string, string) {
+	precision, scale, err := field.DecimalSpec()
+	if err != nil {
+		return "", "Tipo decimal inválido no schema"
+	}
+
+	// Aceita vírgula como separador decimal (pt-BR)
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	if !decimalRegex.MatchString(value) {
+		return "", Messages["number"]
+	}
+
+	intPart, fracPart, _ := strings.Cut(strings.TrimLeft(value, "+-"), ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	if len(fracPart) > scale {
+		return "", fmt.Sprintf(Messages["scale"], scale)
+	}
+	if len(intPart) > precision-scale {
+		return "", fmt.Sprintf(Messages["precision"], precision-scale)
+	}
+	return value, ""
+}
+
+// parseBool interpreta os valores enviados para um campo bool (verdadeiro se algum for marcado)
+func parseBool(values []string) bool {
+	for _, v := range values {
+		switch strings.ToLower(v) {
+		case "1", "true", "on", "sim":
+			return true
+		}
+	}
+	return false
+}
+
 // Helper para remover caracteres não numéricos
 func justDigits(s string) string {
 	var sb strings.Builder
@@ -0,0 +1,282 @@
+package validators
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-crud-generator/models"
+)
+
+// show descreve o valor convertido com o tipo, para distinguir 5 de "5"
+func show(value interface{}) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%T %v", value, value)
+}
+
+func TestValidateFieldTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		field models.Field
+		value string
+		want  string // Valor convertido (ver show), quando válido
+		err   string // Mensagem de erro, quando inválido
+	}{
+		// Inteiros: INT tem 32 bits e BIGINT 64
+		{"int", models.Field{Type: "int"}, "-42", "int -42", ""},
+		{"int máximo", models.Field{Type: "int"}, "2147483647", "int 2147483647", ""},
+		{"int mínimo", models.Field{Type: "int"}, "-2147483648", "int -2147483648", ""},
+		{"int acima", models.Field{Type: "int"}, "2147483648", "", Messages["int_range"]},
+		{"int abaixo", models.Field{Type: "int"}, "-2147483649", "", Messages["int_range"]},
+		{"int com casas", models.Field{Type: "int"}, "1.5", "", Messages["integer"]},
+		{"int texto", models.Field{Type: "int"}, "doze", "", Messages["integer"]},
+		{"bigint máximo", models.Field{Type: "bigint"}, "9223372036854775807", "int64 9223372036854775807", ""},
+		{"bigint mínimo", models.Field{Type: "bigint"}, "-9223372036854775808", "int64 -9223372036854775808", ""},
+		{"bigint acima", models.Field{Type: "bigint"}, "9223372036854775808", "", Messages["bigint_range"]},
+		{"bigint texto", models.Field{Type: "bigint"}, "1e3", "", Messages["integer"]},
+		{"float", models.Field{Type: "float"}, "2.5", "float64 2.5", ""},
+		{"float texto", models.Field{Type: "float"}, "abc", "", Messages["number"]},
+
+		// Decimal: precisão e escala do tipo, vírgula aceita, valor mantido como texto
+		{"decimal", models.Field{Type: "decimal(5,2)"}, "123.45", "string 123.45", ""},
+		{"decimal com vírgula", models.Field{Type: "decimal(5,2)"}, "123,4", "string 123.4", ""},
+		{"decimal com sinal", models.Field{Type: "decimal(5,2)"}, "-0.5", "string -0.5", ""},
+		{"decimal zeros à esquerda", models.Field{Type: "decimal(5,2)"}, "000123.4", "string 000123.4", ""},
+		{"decimal sem parte inteira", models.Field{Type: "decimal(5,2)"}, ".5", "string .5", ""},
+		{"decimal sem casas", models.Field{Type: "decimal(5,2)"}, "12.", "string 12.", ""},
+		{"decimal escala", models.Field{Type: "decimal(5,2)"}, "1.234", "", fmt.Sprintf(Messages["scale"], 2)},
+		{"decimal precisão", models.Field{Type: "decimal(5,2)"}, "1234.5", "", fmt.Sprintf(Messages["precision"], 3)},
+		{"decimal padrão", models.Field{Type: "decimal"}, "12345678.99", "string 12345678.99", ""},
+		{"decimal padrão precisão", models.Field{Type: "decimal"}, "123456789", "", fmt.Sprintf(Messages["precision"], 8)},
+		{"decimal sem escala", models.Field{Type: "decimal(4,0)"}, "1.5", "", fmt.Sprintf(Messages["scale"], 0)},
+		{"decimal longo", models.Field{Type: "decimal(30,10)"}, "12345678901234567890.0123456789", "string 12345678901234567890.0123456789", ""},
+		{"decimal dois separadores", models.Field{Type: "decimal(5,2)"}, "1.2.3", "", Messages["number"]},
+		{"decimal vírgula e ponto", models.Field{Type: "decimal(8,2)"}, "1.234,5", "", Messages["number"]},
+		{"decimal expoente", models.Field{Type: "decimal(5,2)"}, "1e2", "", Messages["number"]},
+
+		// Datas e horas: formatos dos inputs nativos e o brasileiro
+		{"date ISO", models.Field{Type: "date"}, "2024-05-10", show(time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)), ""},
+		{"date BR", models.Field{Type: "date"}, "10/05/2024", show(time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)), ""},
+		{"date inexistente", models.Field{Type: "date"}, "2024-02-30", "", Messages["date"]},
+		{"date americana", models.Field{Type: "date"}, "05-10-2024", "", Messages["date"]},
+		{"datetime local", models.Field{Type: "datetime"}, "2024-05-10T14:30", show(time.Date(2024, 5, 10, 14, 30, 0, 0, time.UTC)), ""},
+		{"datetime com segundos", models.Field{Type: "datetime"}, "2024-05-10T14:30:59", show(time.Date(2024, 5, 10, 14, 30, 59, 0, time.UTC)), ""},
+		{"datetime SQL", models.Field{Type: "datetime"}, "2024-05-10 14:30:59", show(time.Date(2024, 5, 10, 14, 30, 59, 0, time.UTC)), ""},
+		{"datetime BR", models.Field{Type: "datetime"}, "10/05/2024 14:30", show(time.Date(2024, 5, 10, 14, 30, 0, 0, time.UTC)), ""},
+		{"datetime só data", models.Field{Type: "datetime"}, "2024-05-10", "", Messages["datetime"]},
+		{"datetime hora inválida", models.Field{Type: "datetime"}, "2024-05-10T25:00", "", Messages["datetime"]},
+		{"time", models.Field{Type: "time"}, "14:30", "string 14:30:00", ""},
+		{"time com segundos", models.Field{Type: "time"}, "08:05:09", "string 08:05:09", ""},
+		{"time 24h", models.Field{Type: "time"}, "24:00", "", Messages["time"]},
+		{"time sem minutos", models.Field{Type: "time"}, "14", "", Messages["time"]},
+
+		// UUID e JSON
+		{"uuid", models.Field{Type: "uuid"}, "550E8400-E29B-41D4-A716-446655440000", "string 550e8400-e29b-41d4-a716-446655440000", ""},
+		{"uuid curto", models.Field{Type: "uuid"}, "550e8400-e29b-41d4-a716", "", Messages["uuid"]},
+		{"json objeto", models.Field{Type: "json"}, `{"a": [1, 2]}`, `string {"a": [1, 2]}`, ""},
+		{"json escalar", models.Field{Type: "json"}, `"texto"`, `string "texto"`, ""},
+		{"json sem fechar", models.Field{Type: "json"}, `{"a": 1`, "", Messages["json"]},
+		{"json aspas simples", models.Field{Type: "json"}, `{'a': 1}`, "", Messages["json"]},
+		{"json vírgula sobrando", models.Field{Type: "json"}, `[1, 2,]`, "", Messages["json"]},
+
+		// Texto: limite sem os separadores da máscara
+		{"string no limite", models.Field{Type: "string", Length: 3}, "açã", "string açã", ""},
+		{"string acima", models.Field{Type: "string", Length: 3}, "abcd", "", fmt.Sprintf(Messages["max_length"], 3)},
+		{"string com máscara", models.Field{Type: "string", Length: 11, Mask: "999.999.999-99"}, "123.456.789-09", "string 12345678909", ""},
+
+		// Vazio: NULL se opcional, erro se obrigatório
+		{"opcional vazio", models.Field{Type: "int"}, "", "nil", ""},
+		{"obrigatório vazio", models.Field{Type: "int", Required: true}, "", "", Messages["required"]},
+		{"chave vazia", models.Field{Type: "string", PrimaryKey: true}, "", "", Messages["required"]},
+	}
+	for _, tt := range tests {
+		value, msg := ValidateField(tt.field, []string{tt.value})
+		if msg != tt.err {
+			t.Errorf("%s: ValidateField(%s, %q) erro = %q, esperava %q", tt.name, tt.field.Type, tt.value, msg, tt.err)
+			continue
+		}
+		if msg == "" && show(value) != tt.want {
+			t.Errorf("%s: ValidateField(%s, %q) = %s, esperava %s", tt.name, tt.field.Type, tt.value, show(value), tt.want)
+		}
+	}
+}
+
+func TestValidateFieldBool(t *testing.T) {
+	field := models.Field{Type: "bool", Required: true}
+	tests := []struct {
+		values []string
+		want   bool
+	}{
+		{nil, false},               // Obrigatório não se aplica: desmarcado é falso
+		{[]string{"0"}, false},     // Só o campo oculto
+		{[]string{"0", "1"}, true}, // Oculto + checkbox marcado
+		{[]string{"true"}, true},   // JSON / API
+		{[]string{"Sim"}, true},    // Fixtures
+		{[]string{"on"}, true},     // Checkbox sem value
+		{[]string{"não"}, false},
+	}
+	for _, tt := range tests {
+		value, msg := ValidateField(field, tt.values)
+		if msg != "" || value != tt.want {
+			t.Errorf("ValidateField(bool, %q) = %v, %q; esperava %v", tt.values, value, msg, tt.want)
+		}
+	}
+}
+
+func TestFormatFormValue(t *testing.T) {
+	day := time.Date(2024, 5, 10, 14, 30, 59, 0, time.UTC)
+	tests := []struct {
+		field models.Field
+		value interface{}
+		want  string
+	}{
+		{models.Field{Type: "date"}, day, "2024-05-10"},
+		{models.Field{Type: "datetime"}, day, "2024-05-10T14:30"},
+		{models.Field{Type: "bool"}, true, "1"},
+		{models.Field{Type: "bool"}, false, "0"},
+		{models.Field{Type: "json"}, json.RawMessage(`{"a": 1}`), `{"a": 1}`},
+		{models.Field{Type: "string", Mask: "999.999.999-99"}, "12345678909", "123.456.789-09"},
+		{models.Field{Type: "decimal(12,4)"}, "1234.5000", "1234.5000"},
+		{models.Field{Type: "bigint"}, int64(9007199254740993), "9007199254740993"},
+		{models.Field{Type: "time"}, "14:30:59", "14:30:59"},
+	}
+	for _, tt := range tests {
+		if got := FormatFormValue(tt.field, tt.value); got != tt.want {
+			t.Errorf("FormatFormValue(%s, %#v) = %q, esperava %q", tt.field.Type, tt.value, got, tt.want)
+		}
+	}
+
+	// O valor formatado para o formulário volta pela validação sem mudar (o datetime-local
+	// não tem segundos)
+	for _, tt := range []struct {
+		field models.Field
+		want  time.Time
+	}{
+		{models.Field{Type: "date"}, time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)},
+		{models.Field{Type: "datetime"}, time.Date(2024, 5, 10, 14, 30, 0, 0, time.UTC)},
+	} {
+		value, msg := ValidateField(tt.field, []string{FormatFormValue(tt.field, day)})
+		if got, _ := value.(time.Time); msg != "" || !got.Equal(tt.want) {
+			t.Errorf("%s: o valor do formulário volta da validação como %v, %q", tt.field.Type, value, msg)
+		}
+	}
+}
+
+func TestFormatDisplayValue(t *testing.T) {
+	day := time.Date(2024, 5, 10, 14, 30, 59, 0, time.UTC)
+	tests := []struct {
+		field models.Field
+		value interface{}
+		want  string
+	}{
+		{models.Field{Type: "date"}, day, "10/05/2024"},
+		{models.Field{Type: "datetime"}, day, "10/05/2024 14:30"},
+		{models.Field{Type: "bool"}, true, "Sim"},
+		{models.Field{Type: "bool"}, false, "Não"},
+		{models.Field{Type: "json"}, json.RawMessage("{\n  \"a\": [1, 2]\n}"), `{"a":[1,2]}`},
+		{models.Field{Type: "time"}, "14:30:59", "14:30"},
+		{models.Field{Type: "string", Mask: "(99) 99999-9999"}, "11987654321", "(11) 98765-4321"},
+	}
+	for _, tt := range tests {
+		if got := FormatDisplayValue(tt.field, tt.value); got != tt.want {
+			t.Errorf("FormatDisplayValue(%s, %#v) = %q, esperava %q", tt.field.Type, tt.value, got, tt.want)
+		}
+	}
+}
+
+// partialTestSchema tem uma regra entre campos e um campo obrigatório
+func partialTestSchema() *models.Schema {
+	return &models.Schema{
+		TableName: "pedidos",
+		Fields: []models.Field{
+			{Name: "id", Type: "int", PrimaryKey: true},
+			{Name: "cliente", Type: "string", Required: true},
+			{Name: "inicio", Type: "date", Required: true},
+			{Name: "fim", Type: "date"},
+			{Name: "obs", Type: "text"},
+			{Name: "anexo", Type: "file"},
+		},
+		Rules: []models.Rule{
+			{Check: "fim >= inicio", Fields: []string{"fim"}, Message: "O fim deve ser depois do início"},
+		},
+	}
+}
+
+func TestValidatePartial(t *testing.T) {
+	schema := partialTestSchema()
+
+	// Só os campos enviados entram no resultado: os ausentes não são alterados
+	data, errs := ValidatePartial(url.Values{"obs": {"nova"}}, schema)
+	if len(errs) > 0 || !reflect.DeepEqual(data, map[string]interface{}{"obs": "nova"}) {
+		t.Errorf("só obs: dados %v, erros %v", data, errs)
+	}
+
+	// Campo enviado vazio grava NULL; o obrigatório vale só para os campos enviados
+	data, errs = ValidatePartial(url.Values{"obs": {""}, "cliente": {""}}, schema)
+	if _, ok := data["obs"]; !ok || data["obs"] != nil {
+		t.Errorf("obs vazio deveria gravar NULL: %v", data)
+	}
+	if !reflect.DeepEqual(errs, map[string]string{"cliente": Messages["required"]}) {
+		t.Errorf("cliente vazio: erros %v", errs)
+	}
+
+	// A chave gerada e os uploads nunca vêm do formulário
+	data, _ = ValidatePartial(url.Values{"id": {"9"}, "anexo": {"x.pdf"}}, schema)
+	if len(data) != 0 {
+		t.Errorf("chave e upload não deveriam ser alterados: %v", data)
+	}
+
+	// As regras entre campos ficam com CheckRules
+	data, errs = ValidatePartial(url.Values{"fim": {"2024-01-01"}}, schema)
+	if len(errs) > 0 || len(data) != 1 {
+		t.Errorf("ValidatePartial não deveria avaliar as regras: dados %v, erros %v", data, errs)
+	}
+}
+
+// Numa atualização parcial, a regra é avaliada sobre o registro gravado com as alterações
+func TestCheckRulesPartial(t *testing.T) {
+	schema := partialTestSchema()
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	stored := map[string]interface{}{"id": 1, "cliente": "Ana", "inicio": day(10), "fim": day(20), "obs": nil}
+	merge := func(changes map[string]interface{}) map[string]interface{} {
+		merged := map[string]interface{}{}
+		for k, v := range stored {
+			merged[k] = v
+		}
+		for k, v := range changes {
+			merged[k] = v
+		}
+		return merged
+	}
+
+	tests := []struct {
+		name    string
+		changed map[string]interface{}
+		want    map[string]string
+	}{
+		{"fim antes do início gravado", map[string]interface{}{"fim": day(5)}, map[string]string{"fim": "O fim deve ser depois do início"}},
+		{"início depois do fim gravado", map[string]interface{}{"inicio": day(25)}, map[string]string{"fim": "O fim deve ser depois do início"}},
+		{"fim depois do início gravado", map[string]interface{}{"fim": day(15)}, map[string]string{}},
+		{"campo fora da regra", map[string]interface{}{"obs": "x"}, map[string]string{}},
+	}
+	for _, tt := range tests {
+		errs := map[string]string{}
+		CheckRules(schema, merge(tt.changed), tt.changed, errs)
+		if !reflect.DeepEqual(errs, tt.want) {
+			t.Errorf("%s: erros %v, esperava %v", tt.name, errs, tt.want)
+		}
+	}
+
+	// Uma regra que já falha no registro gravado só é cobrada quando um campo dela muda
+	stored["fim"] = day(1)
+	errs := map[string]string{}
+	CheckRules(schema, merge(map[string]interface{}{"obs": "x"}), map[string]interface{}{"obs": "x"}, errs)
+	if len(errs) > 0 {
+		t.Errorf("regra sem campo alterado não deveria ser avaliada: %v", errs)
+	}
+}